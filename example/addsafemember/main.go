@@ -1,8 +1,8 @@
 package main
 
 import (
+	"errors"
 	"log"
-	"net/http"
 
 	"github.com/davidh-cyberark/privilegeaccessmanager-sdk-go/pam"
 	"github.com/knadh/koanf/parsers/toml"
@@ -37,24 +37,22 @@ func main() {
 	}
 
 	addsaferesp, respcode, err := client.AddSafe(newsafe)
-	if err != nil {
-		log.Fatalf("Error: could not add safe: %s", err.Error())
-	}
-	if respcode != http.StatusOK {
-		if addsaferesp.ErrorResponse.ErrorCode == "SFWS0002" {
-			safedetails, respcode, err := client.GetSafeDetails(newsafe.SafeName)
-			if err != nil || respcode >= 300 {
-				log.Fatalf("Error: not able to fetch details about existing safe: (%d) %s", respcode, err.Error())
-			}
-			addsaferesp.SafeURLID = safedetails.SafeURLID
-			addsaferesp.SafeName = safedetails.SafeName
-			addsaferesp.SafeNumber = safedetails.SafeNumber
-			addsaferesp.Description = safedetails.Description
-			addsaferesp.Location = safedetails.Location
-
-		} else {
-			log.Fatalf("Error: (%d) %s: %s", respcode, addsaferesp.ErrorCode, addsaferesp.ErrorMessage)
+	if pam.IsAlreadyExists(err) {
+		safedetails, respcode, err := client.GetSafeDetails(newsafe.SafeName)
+		if err != nil {
+			log.Fatalf("Error: not able to fetch details about existing safe: (%d) %s", respcode, err.Error())
+		}
+		addsaferesp.SafeURLID = safedetails.SafeURLID
+		addsaferesp.SafeName = safedetails.SafeName
+		addsaferesp.SafeNumber = safedetails.SafeNumber
+		addsaferesp.Description = safedetails.Description
+		addsaferesp.Location = safedetails.Location
+	} else if err != nil {
+		var apierr *pam.APIError
+		if errors.As(err, &apierr) {
+			log.Fatalf("Error: (%d) %s: %s", apierr.StatusCode, apierr.ErrorCode, apierr.ErrorMessage)
 		}
+		log.Fatalf("Error: could not add safe: %s", err.Error())
 	}
 	log.Printf("Response Code: %d\nSafeURLID: %s\nSafeName: %s\nSafeNumber: %d\nDescription: %s\nLocation: %s\n",
 		respcode,
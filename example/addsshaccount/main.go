@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"log"
+	"os"
 
 	"github.com/davidh-cyberark/privilegeaccessmanager-sdk-go/pam"
 	"github.com/knadh/koanf/parsers/toml"
@@ -18,8 +19,17 @@ user = "PAM_SERVICE_ACCOUNT_USER"
 pass = "PAM_SERVICE_ACCOUNT_USER password"
 */
 func main() {
+	if len(os.Args) < 2 {
+		log.Fatalf("Usage: %s <private_key_file>", os.Args[0])
+	}
+	keyfile, err := os.Open(os.Args[1])
+	if err != nil {
+		log.Fatalf("failed to open private key: %s", err.Error())
+	}
+	defer keyfile.Close()
+
 	k := koanf.New(".")
-	err := k.Load(file.Provider("creds.toml"), toml.Parser())
+	err = k.Load(file.Provider("creds.toml"), toml.Parser())
 	if err != nil {
 		log.Fatalf("failed to load creds.toml: %s", err.Error())
 	}
@@ -31,11 +41,17 @@ func main() {
 	newaccount := pam.PostAddAccountRequest{
 		Name:       "mynewaccount1",
 		SafeName:   "my-new-safe-1", // required
-		PlatformID: "UnixSSH",
+		PlatformID: "UnixSSHKeys",
 		Address:    "127.0.0.1",
 		UserName:   "oscar",
 	}
 
+	newaccount, fingerprint, err := pam.NewSSHKeyAccountRequest(newaccount, keyfile)
+	if err != nil {
+		log.Fatalf("Error: %s", err.Error())
+	}
+	fmt.Printf("Key Fingerprint: %s\n", fingerprint)
+
 	resp, respcode, err := client.AddAccount(newaccount)
 	if err != nil {
 		log.Fatalf("Error: could not add account: (%d) %s", respcode, err.Error())
@@ -6,6 +6,7 @@ package main
 // the Safe where the account is located inside the Vault.
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -45,22 +46,25 @@ func main() {
 	client := pam.NewClient(k.String("pcloudurl"), config)
 	client.RefreshSession()
 
-	filter := fmt.Sprintf("safeName eq %s", safename)
-	var searchp *string = nil
-	var searchtypep *string = nil
+	opts := pam.ListAccountsOptions{
+		Filter: pam.SafeName(pam.Eq, safename),
+		Sort:   []pam.Sort{pam.Asc("name")},
+	}
 	if len(acctname) > 0 {
-		search := fmt.Sprintf("search=%s", acctname)
-		searchp = &search
-		searchtype := "startswith"
-		searchtypep = &searchtype
+		opts.Search = acctname
+		opts.SearchType = pam.SearchStartsWith
 	}
 
-	resp, respcode, err := client.GetAccounts(searchp, searchtypep, nil, &filter, nil, nil, nil)
-	if err != nil {
-		log.Fatalf("Error: could not get accounts: (%d) %s", respcode, err.Error())
+	accounts := []pam.GetAccountResponse{}
+	pager := client.ListAccountsPager(context.Background(), opts)
+	for pager.Next() {
+		accounts = append(accounts, pager.Item())
+	}
+	if err := pager.Err(); err != nil {
+		log.Fatalf("Error: could not get accounts: %s", err.Error())
 	}
 
-	jsonData, err := json.Marshal(resp)
+	jsonData, err := json.Marshal(accounts)
 	if err != nil {
 		log.Fatalf("Error marshaling response to JSON: %s", err.Error())
 	}
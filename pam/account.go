@@ -1,12 +1,9 @@
 package pam
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
-	"log"
 	"net/http"
-	"net/url"
 	"strconv"
 	"strings"
 	"time"
@@ -26,6 +23,35 @@ type SecretManagement struct {
 	LastModifiedDateTime       time.Time `json:"lastModifiedDateTime,omitempty"`
 	LastReconciledDateTime     time.Time `json:"lastReconciledDateTime,omitempty"`
 	LastVerifiedDateTime       time.Time `json:"lastVerifiedDateTime,omitempty"`
+	LastModifiedTime           int64     `json:"lastModifiedTime,omitempty"`   // epoch seconds, as returned by Get Account
+	LastReconciledTime         int64     `json:"lastReconciledTime,omitempty"` // epoch seconds, as returned by Get Account
+	LastVerifiedTime           int64     `json:"lastVerifiedTime,omitempty"`   // epoch seconds, as returned by Get Account
+}
+
+// lastModified returns when the CPM last modified the secret, from whichever field the API filled in
+func (sm SecretManagement) lastModified() time.Time {
+	return cpmTime(sm.LastModifiedDateTime, sm.LastModifiedTime)
+}
+
+// lastReconciled returns when the CPM last reconciled the secret
+func (sm SecretManagement) lastReconciled() time.Time {
+	return cpmTime(sm.LastReconciledDateTime, sm.LastReconciledTime)
+}
+
+// lastVerified returns when the CPM last verified the secret
+func (sm SecretManagement) lastVerified() time.Time {
+	return cpmTime(sm.LastVerifiedDateTime, sm.LastVerifiedTime)
+}
+
+// cpmTime returns datetime if set, otherwise the epoch seconds
+func cpmTime(datetime time.Time, epoch int64) time.Time {
+	if !datetime.IsZero() {
+		return datetime
+	}
+	if epoch > 0 {
+		return time.Unix(epoch, 0)
+	}
+	return time.Time{}
 }
 
 // PostAddAccountRequest is used to create an account
@@ -74,14 +100,18 @@ type GetAccountResponse struct {
 }
 
 type GetAccountsResponse struct {
-	Value []GetAccountResponse `json:"value,omitempty"`
-	Count int                  `json:"count,omitempty"`
+	Value    []GetAccountResponse `json:"value,omitempty"`
+	Count    int                  `json:"count,omitempty"`
+	NextLink string               `json:"nextLink,omitempty"`
 }
 
 func (c *Client) GetAccounts(search, searchtype, sort, filter, savedfilter, offset, limit *string) (*GetAccountsResponse, int, error) {
-	// https://<subdomain>.privilegecloud.cyberark.cloud/PasswordVault/API/Accounts?search={search}&searchType={searchType}&sort={sort}&offset={offset}&limit={limit}&filter={filter}/
+	return c.GetAccountsWithContext(context.Background(), search, searchtype, sort, filter, savedfilter, offset, limit)
+}
 
-	accountresp := GetAccountsResponse{}
+// GetAccountsWithContext is GetAccounts with a context that can cancel the request
+func (c *Client) GetAccountsWithContext(ctx context.Context, search, searchtype, sort, filter, savedfilter, offset, limit *string) (*GetAccountsResponse, int, error) {
+	// https://<subdomain>.privilegecloud.cyberark.cloud/PasswordVault/API/Accounts?search={search}&searchType={searchType}&sort={sort}&offset={offset}&limit={limit}&filter={filter}/
 
 	qpathparts := map[string]string{}
 	if search != nil {
@@ -89,7 +119,7 @@ func (c *Client) GetAccounts(search, searchtype, sort, filter, savedfilter, offs
 	}
 	if searchtype != nil {
 		// Valid values:  contains (default) or startswith
-		if *searchtype != "contains" && *searchtype != "startswith" {
+		if !SearchType(*searchtype).valid() {
 			return nil, http.StatusBadRequest, fmt.Errorf("invalid searchType: %s, must be 'contains' or 'startswith'", *searchtype)
 		}
 		qpathparts["searchType"] = *searchtype
@@ -114,35 +144,19 @@ func (c *Client) GetAccounts(search, searchtype, sort, filter, savedfilter, offs
 		qpathparts["filter"] = *filter
 	}
 	if savedfilter != nil {
-		validSavedFilters := []string{
-			"Regular", "Recently", "New", "Link", "Deleted", "PolicyFailures",
-			"AccessedByUsers", "ModifiedByUsers", "ModifiedByCPM", "DisabledPasswordByUser",
-			"DisabledPasswordByCPM", "ScheduledForChange", "ScheduledForVerify",
-			"ScheduledForReconcile", "SuccessfullyReconciled", "FailedChange",
-			"FailedVerify", "FailedReconcile", "LockedOrNew", "Locked",
-			"Favorites", "DeleteInsightStatus",
-		}
-
-		isValidSavedFilter := false
-		for _, validSavedFilter := range validSavedFilters {
-			if *savedfilter == validSavedFilter {
-				isValidSavedFilter = true
-				break
-			}
-		}
-		if !isValidSavedFilter {
-			return nil, http.StatusBadRequest, fmt.Errorf("invalid savedfilter: %s, must be one of: %s", *savedfilter, strings.Join(validSavedFilters, ", "))
+		if !SavedFilter(*savedfilter).valid() {
+			return nil, http.StatusBadRequest, fmt.Errorf("invalid savedfilter: %s, must be one of: %s", *savedfilter, joinSavedFilters())
 		}
 		qpathparts["savedfilter"] = *savedfilter
 	}
 
 	if offset != nil {
-		o, e := strconv.Atoi(*limit)
+		o, e := strconv.Atoi(*offset)
 		if e != nil {
 			return nil, http.StatusBadRequest, fmt.Errorf("offset is not a number, got %s", *offset)
 		}
 		if o < 0 {
-			return nil, http.StatusBadRequest, fmt.Errorf("offset must be greater than 0, got %s", *offset)
+			return nil, http.StatusBadRequest, fmt.Errorf("offset must be 0 or greater, got %s", *offset)
 		}
 
 		qpathparts["offset"] = *offset
@@ -158,135 +172,86 @@ func (c *Client) GetAccounts(search, searchtype, sort, filter, savedfilter, offs
 		qpathparts["limit"] = *limit
 	}
 
-	qpath := ""
-	var qparts []string
-	if len(qpathparts) > 0 {
-		for key, value := range qpathparts {
-			valueenc := url.QueryEscape(value) // Escape the value parts
-			qparts = append(qparts, fmt.Sprintf("%s=%s", key, valueenc))
-		}
-		qpath = fmt.Sprintf("?%s", strings.Join(qparts, "&"))
-	}
+	return c.getAccounts(ctx, qpathparts)
+}
 
-	// https://<PVWA_Server_address>/PasswordVault/API/Accounts/{id}/
-	apiurl := fmt.Sprintf("%s/PasswordVault/API/Accounts%s", c.Config.PcloudUrl, qpath)
+// getAccounts fetches one page of accounts for the already validated query parameters
+func (c *Client) getAccounts(ctx context.Context, qpathparts map[string]string) (*GetAccountsResponse, int, error) {
+	accountresp := GetAccountsResponse{}
 
-	req, err := http.NewRequest(http.MethodGet, apiurl, nil)
-	if err != nil {
-		return nil, http.StatusConflict, fmt.Errorf("failed to create new request for get account: %s", err.Error())
-	}
-	// attach the header
-	req.Header = make(http.Header)
-	req.Header.Add("Content-Type", "application/json")
+	// GET /PasswordVault/API/Accounts?search={search}&searchType={searchType}&sort={sort}&offset={offset}&limit={limit}&filter={filter}
+	apiurl := c.apiURL(qpathparts, "Accounts")
 
-	res, err := c.SendRequest(req)
+	req, err := newJSONRequest(ctx, http.MethodGet, apiurl, "get accounts", nil)
 	if err != nil {
-		return nil, http.StatusBadGateway, fmt.Errorf("failed to send get acount request. %s", err.Error())
+		return nil, http.StatusConflict, err
 	}
 
-	// read response body
-	body, error := io.ReadAll(res.Body)
-	if error != nil {
-		log.Println(error)
-	}
-	// close response body
-	defer res.Body.Close()
-
-	if res.StatusCode >= 300 {
-		return &accountresp, res.StatusCode, fmt.Errorf("received non-200 status code(%d): %s", res.StatusCode, string(body))
-	}
-
-	err = json.Unmarshal(body, &accountresp)
+	status, err := c.do(req, "get accounts", &accountresp)
 	if err != nil {
-		return &accountresp, res.StatusCode, fmt.Errorf("response format failed to parse: %s: %s", err.Error(), string(body))
+		return &accountresp, status, err
 	}
 
 	return &accountresp, http.StatusOK, nil
 }
 
+// GetAccountsPager walks every account matching the search, fetching pageSize
+// accounts per request (default 100, max 1000)
+func (c *Client) GetAccountsPager(ctx context.Context, search, searchtype, sort, filter, savedfilter *string, pageSize int) *Pager[GetAccountResponse] {
+	return newPager(ctx, 0, pageSize, func(ctx context.Context, offset, limit int) ([]GetAccountResponse, int, bool, error) {
+		o, l := strconv.Itoa(offset), strconv.Itoa(limit)
+		resp, _, err := c.GetAccountsWithContext(ctx, search, searchtype, sort, filter, savedfilter, &o, &l)
+		if err != nil {
+			return nil, 0, false, err
+		}
+		return resp.Value, resp.Count, resp.NextLink != "", nil
+	})
+}
+
 func (c *Client) GetAccount(acctid string) (GetAccountResponse, int, error) {
+	return c.GetAccountWithContext(context.Background(), acctid)
+}
+
+// GetAccountWithContext is GetAccount with a context that can cancel the request
+func (c *Client) GetAccountWithContext(ctx context.Context, acctid string) (GetAccountResponse, int, error) {
 	accountresp := GetAccountResponse{}
 
 	// https://<PVWA_Server_address>/PasswordVault/API/Accounts/{id}/
-	apiurl := fmt.Sprintf("%s/PasswordVault/API/Accounts/%s", c.Config.PcloudUrl, acctid)
-
-	req, err := http.NewRequest(http.MethodGet, apiurl, nil)
-	if err != nil {
-		return accountresp, http.StatusConflict, fmt.Errorf("failed to create new request for get account: %s", err.Error())
-	}
-	// attach the header
-	req.Header = make(http.Header)
-	req.Header.Add("Content-Type", "application/json")
+	apiurl := c.apiURL(nil, "Accounts", acctid)
 
-	res, err := c.SendRequest(req)
+	req, err := newJSONRequest(ctx, http.MethodGet, apiurl, "get account", nil)
 	if err != nil {
-		return accountresp, http.StatusBadGateway, fmt.Errorf("failed to send get acount request. %s", err.Error())
-	}
-
-	// read response body
-	body, error := io.ReadAll(res.Body)
-	if error != nil {
-		log.Println(error)
+		return accountresp, http.StatusConflict, err
 	}
-	// close response body
-	defer res.Body.Close()
 
-	err = json.Unmarshal(body, &accountresp)
+	status, err := c.do(req, "get account", &accountresp)
 	if err != nil {
-		return accountresp, res.StatusCode, fmt.Errorf("response format failed to parse: %s: %s", err.Error(), string(body))
-	}
-	if res.StatusCode >= 300 {
-		return accountresp, res.StatusCode, fmt.Errorf("received non-200 status code(%d): %s", res.StatusCode, string(body))
+		return accountresp, status, err
 	}
 
 	return accountresp, http.StatusOK, nil
 }
 
 func (c *Client) AddAccount(accountreq PostAddAccountRequest) (PostAddAccountResponse, int, error) {
+	return c.AddAccountWithContext(context.Background(), accountreq)
+}
+
+// AddAccountWithContext is AddAccount with a context that can cancel the request
+func (c *Client) AddAccountWithContext(ctx context.Context, accountreq PostAddAccountRequest) (PostAddAccountResponse, int, error) {
 	// https://docs.cyberark.com/privilege-cloud-shared-services/latest/en/Content/WebServices/Add%20Safe.htm
 	accountresp := PostAddAccountResponse{}
 
 	// POST /PasswordVault/API/Accounts/
-	apiurl := fmt.Sprintf("%s/PasswordVault/API/Accounts/", c.Config.PcloudUrl)
-
-	jsonbody, err := json.Marshal(accountreq)
-	if err != nil {
-		return accountresp,
-			http.StatusConflict,
-			fmt.Errorf("failed to parse json body for add account request: %s", err.Error())
-	}
+	apiurl := c.apiURL(nil, "Accounts", "")
 
-	req, err := http.NewRequest(http.MethodPost, apiurl, strings.NewReader(string(jsonbody)))
+	req, err := newJSONRequest(ctx, http.MethodPost, apiurl, "add account", accountreq)
 	if err != nil {
-		return accountresp,
-			http.StatusConflict,
-			fmt.Errorf("failed to create new request for add account: %s", err.Error())
+		return accountresp, http.StatusConflict, err
 	}
-	// attach the header
-	req.Header = make(http.Header)
-	req.Header.Add("Content-Type", "application/json")
 
-	res, err := c.SendRequest(req)
+	status, err := c.do(req, "add account", &accountresp)
 	if err != nil {
-		return accountresp,
-			http.StatusBadGateway,
-			fmt.Errorf("failed to send add acount request. %s", err.Error())
-	}
-
-	// read response body
-	body, error := io.ReadAll(res.Body)
-	if error != nil {
-		log.Println(error)
-	}
-	// close response body
-	defer res.Body.Close()
-
-	err = json.Unmarshal(body, &accountresp)
-	if err != nil {
-		return accountresp, res.StatusCode, fmt.Errorf("response format failed to parse: %s: %s", err.Error(), string(body))
-	}
-	if res.StatusCode >= 300 {
-		return accountresp, res.StatusCode, fmt.Errorf("received non-200 status code(%d): %s", res.StatusCode, string(body))
+		return accountresp, status, err
 	}
 
 	return accountresp, http.StatusOK, nil
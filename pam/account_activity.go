@@ -0,0 +1,107 @@
+package pam
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// AccountActivity is one entry in an account's audit trail
+type AccountActivity struct {
+	User     string `json:"User,omitempty"`
+	Action   string `json:"Action,omitempty"` // Ex: "Retrieve Password", "CPM Change Password"
+	ActionID int    `json:"ActionID,omitempty"`
+	Date     int64  `json:"Date,omitempty"` // epoch seconds
+	Reason   string `json:"Reason,omitempty"`
+	Alert    bool   `json:"Alert,omitempty"`
+	ClientID string `json:"ClientID,omitempty"`
+	MoreInfo string `json:"MoreInfo,omitempty"`
+}
+
+// Time returns when the activity happened
+func (a AccountActivity) Time() time.Time {
+	return time.Unix(a.Date, 0)
+}
+
+type GetAccountActivitiesResponse struct {
+	Activities []AccountActivity `json:"Activities,omitempty"`
+	Total      int               `json:"Total,omitempty"`
+}
+
+// ActivityFilter selects account activities, see Filter
+type ActivityFilter func(AccountActivity) bool
+
+// ActivitiesBetween selects activities from start up to, but not including, end;
+// a zero start or end leaves that side open
+func ActivitiesBetween(start, end time.Time) ActivityFilter {
+	return func(a AccountActivity) bool {
+		t := a.Time()
+		return (start.IsZero() || !t.Before(start)) && (end.IsZero() || t.Before(end))
+	}
+}
+
+// ActivitiesWithAction selects activities whose action is one of actions, ignoring case
+func ActivitiesWithAction(actions ...string) ActivityFilter {
+	return func(a AccountActivity) bool {
+		for _, action := range actions {
+			if strings.EqualFold(a.Action, action) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// ActivitiesByUser selects activities performed by user, ignoring case
+func ActivitiesByUser(user string) ActivityFilter {
+	return func(a AccountActivity) bool {
+		return strings.EqualFold(a.User, user)
+	}
+}
+
+// ActivityAlerts selects activities that raised an alert
+func ActivityAlerts() ActivityFilter {
+	return func(a AccountActivity) bool {
+		return a.Alert
+	}
+}
+
+// Filter returns the activities that match all filters
+func (r GetAccountActivitiesResponse) Filter(filters ...ActivityFilter) []AccountActivity {
+	matched := []AccountActivity{}
+	for _, activity := range r.Activities {
+		ok := true
+		for _, filter := range filters {
+			if !filter(activity) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			matched = append(matched, activity)
+		}
+	}
+	return matched
+}
+
+// GetAccountActivities returns the account's activity history; requires View Audit permission
+// REF: <https://docs.cyberark.com/privilege-cloud-shared-services/latest/en/content/webservices/get%20account%20activity.htm>
+func (c *Client) GetAccountActivities(ctx context.Context, acctid string) (GetAccountActivitiesResponse, int, error) {
+	activities := GetAccountActivitiesResponse{}
+
+	// GET /PasswordVault/API/Accounts/{id}/Activities/
+	apiurl := c.apiURL(nil, "Accounts", acctid, "Activities")
+
+	req, err := newJSONRequest(ctx, http.MethodGet, apiurl, "get account activities", nil)
+	if err != nil {
+		return activities, http.StatusConflict, err
+	}
+
+	status, err := c.do(req, "get account activities", &activities)
+	if err != nil {
+		return activities, status, err
+	}
+
+	return activities, http.StatusOK, nil
+}
@@ -0,0 +1,39 @@
+package pam
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// CheckIn releases an account retrieved under an exclusive access
+// (EnforceCheckinCheckoutExclusiveAccess) platform, so others can use it
+func (c *Client) CheckIn(ctx context.Context, acctid string) (int, error) {
+	return c.accountAction(ctx, acctid, "check in account", nil, "CheckIn")
+}
+
+// Unlock releases an account locked by another user; requires Unlock Accounts permission
+func (c *Client) Unlock(ctx context.Context, acctid string) (int, error) {
+	return c.accountAction(ctx, acctid, "unlock account", nil, "Unlock")
+}
+
+// WithCheckedOutSecret retrieves the account's secret, calls fn with it, then
+// zeroes the secret and checks the account back in, even if fn panics.  The
+// check in is not cancelled with ctx, so a cancelled fn does not leave the
+// account locked.
+func (c *Client) WithCheckedOutSecret(ctx context.Context, acctid string, opts RetrieveSecretOptions, fn func(*Secret) error) (err error) {
+	secret, _, err := c.RetrieveSecret(ctx, acctid, opts)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		secret.Close()
+		_, checkinerr := c.CheckIn(context.WithoutCancel(ctx), acctid)
+		if checkinerr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to check in account %s: %w", acctid, checkinerr))
+		}
+	}()
+
+	return fn(secret)
+}
@@ -0,0 +1,51 @@
+package pam
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestWithCheckedOutSecretPanic(t *testing.T) {
+	var checkins []string
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/PasswordVault/API/Accounts/12_3/Password/Retrieve":
+			fmt.Fprint(w, `"hunter2"`)
+		case "/PasswordVault/API/Accounts/12_3/CheckIn":
+			checkins = append(checkins, r.Method)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	client := NewClient(srv.URL, NewConfig("", srv.URL, "", ""))
+
+	var secret *Secret
+	var value []byte
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("recover() = %v, want boom", r)
+			}
+		}()
+		ctx, cancel := context.WithCancel(context.Background())
+		client.WithCheckedOutSecret(ctx, "12_3", RetrieveSecretOptions{}, func(s *Secret) error {
+			secret, value = s, s.Bytes()
+			// the check in must still be sent after ctx is cancelled
+			cancel()
+			panic("boom")
+		})
+	}()
+
+	if len(checkins) != 1 || checkins[0] != http.MethodPost {
+		t.Errorf("check in requests = %v, want one POST", checkins)
+	}
+	if secret == nil || secret.Bytes() != nil {
+		t.Fatalf("secret not closed")
+	}
+	if len(value) != len("hunter2") || !bytes.Equal(value, make([]byte, len(value))) {
+		t.Errorf("secret value = %q, want zeroed", value)
+	}
+}
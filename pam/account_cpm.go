@@ -0,0 +1,136 @@
+package pam
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// CPM secret management statuses reported in SecretManagement.Status
+const (
+	CPMStatusSuccess = "success"
+	CPMStatusFailure = "failure"
+)
+
+// CPMOperation is the CPM operation WaitForCPMOperation waits for
+type CPMOperation int
+
+const (
+	CPMChange    CPMOperation = iota // ChangeCredentials, SetNextPassword
+	CPMVerify                        // VerifyCredentials
+	CPMReconcile                     // ReconcileCredentials
+)
+
+// ErrCPMOperationFailed is returned by WaitForCPMOperation when the CPM reports failure
+var ErrCPMOperationFailed = errors.New("CPM operation failed")
+
+// defaultCPMPollInterval is how often WaitForCPMOperation checks the account by default
+const defaultCPMPollInterval = 10 * time.Second
+
+type changeCredentialsRequest struct {
+	ChangeEntireGroup bool   `json:"ChangeEntireGroup,omitempty"`
+	ChangeImmediately bool   `json:"ChangeImmediately,omitempty"`
+	NewCredentials    string `json:"NewCredentials,omitempty"`
+}
+
+// ChangeCredentials marks the account for an immediate CPM change to a
+// generated secret, and its whole account group if changeEntireGroup
+// REF: <https://docs.cyberark.com/privilege-cloud-shared-services/latest/en/content/webservices/change-credentials-immediately.htm>
+func (c *Client) ChangeCredentials(ctx context.Context, acctid string, changeEntireGroup bool) (int, error) {
+	return c.accountAction(ctx, acctid, "change credentials", changeCredentialsRequest{ChangeEntireGroup: changeEntireGroup}, "Change")
+}
+
+// SetNextPassword sets the secret the CPM changes to next; if changeImmediately
+// the change is made now, otherwise at the next scheduled change
+func (c *Client) SetNextPassword(ctx context.Context, acctid string, newcredentials string, changeImmediately bool) (int, error) {
+	body := changeCredentialsRequest{ChangeImmediately: changeImmediately, NewCredentials: newcredentials}
+	return c.accountAction(ctx, acctid, "set next password", body, "SetNextPassword")
+}
+
+// ChangeCredentialsInVault sets the secret stored in the vault only, without
+// changing it on the target machine
+func (c *Client) ChangeCredentialsInVault(ctx context.Context, acctid string, newcredentials string, changeEntireGroup bool) (int, error) {
+	body := changeCredentialsRequest{ChangeEntireGroup: changeEntireGroup, NewCredentials: newcredentials}
+	return c.accountAction(ctx, acctid, "change credentials in vault", body, "Password", "Update")
+}
+
+// VerifyCredentials marks the account for verification by the CPM
+func (c *Client) VerifyCredentials(ctx context.Context, acctid string) (int, error) {
+	return c.accountAction(ctx, acctid, "verify credentials", nil, "Verify")
+}
+
+// ReconcileCredentials marks the account for reconciliation by the CPM, using its reconcile account
+func (c *Client) ReconcileCredentials(ctx context.Context, acctid string) (int, error) {
+	return c.accountAction(ctx, acctid, "reconcile credentials", nil, "Reconcile")
+}
+
+// accountAction posts body to /Accounts/{id}/<action...>, for operations with no response body
+func (c *Client) accountAction(ctx context.Context, acctid, op string, body any, action ...string) (int, error) {
+	// POST /PasswordVault/API/Accounts/{id}/{action}/
+	apiurl := c.apiURL(nil, append([]string{"Accounts", acctid}, action...)...)
+
+	req, err := newJSONRequest(ctx, http.MethodPost, apiurl, op, body)
+	if err != nil {
+		return http.StatusConflict, err
+	}
+
+	status, err := c.do(req, op, nil)
+	if err != nil {
+		return status, err
+	}
+	return http.StatusOK, nil
+}
+
+// WaitForCPMOperation polls the account every interval (default 10s) until
+// the CPM reports a result for op.  before is the account's SecretManagement
+// from a GetAccount made before the operation was requested; if nil, the
+// first poll is used, which misses a result the CPM reported before it.  A
+// result is a change to the status or to op's timestamp: the last modified
+// time for a change, the last verified time for a verify, or the last
+// reconciled time for a reconcile.  These only move on success, so a status
+// that turns to failure is reported as ErrCPMOperationFailed.  The times are
+// the vault's, so the client's clock does not matter.  It returns the account.
+func (c *Client) WaitForCPMOperation(ctx context.Context, acctid string, op CPMOperation, before *SecretManagement, interval time.Duration) (GetAccountResponse, error) {
+	var last func(SecretManagement) time.Time
+	switch op {
+	case CPMChange:
+		last = SecretManagement.lastModified
+	case CPMVerify:
+		last = SecretManagement.lastVerified
+	case CPMReconcile:
+		last = SecretManagement.lastReconciled
+	default:
+		return GetAccountResponse{}, fmt.Errorf("unknown CPM operation: %d", op)
+	}
+	if interval <= 0 {
+		interval = defaultCPMPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		acct, _, err := c.GetAccountWithContext(ctx, acctid)
+		if err != nil {
+			return acct, err
+		}
+		sm := acct.SecretManagement
+		if before == nil {
+			before = &sm
+		} else if moved := !last(sm).Equal(last(*before)); moved || sm.Status != before.Status {
+			switch {
+			case sm.Status == CPMStatusFailure:
+				return acct, fmt.Errorf("account %s: %w", acctid, ErrCPMOperationFailed)
+			case sm.Status == CPMStatusSuccess && moved:
+				return acct, nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return acct, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package pam
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// cpmServer returns first for the first poll and then for every later poll
+func cpmServer(t *testing.T, first, then SecretManagement) *Client {
+	t.Helper()
+	var polls atomic.Int32
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		sm := then
+		if polls.Add(1) == 1 {
+			sm = first
+		}
+		json.NewEncoder(w).Encode(GetAccountResponse{ID: "1", SecretManagement: sm})
+	})
+	return NewClient(srv.URL, NewConfig("", srv.URL, "", ""))
+}
+
+func TestWaitForCPMOperation(t *testing.T) {
+	// vault times an hour ahead of the client's clock
+	vault := time.Now().Add(time.Hour).Unix()
+	before := SecretManagement{Status: CPMStatusSuccess, LastModifiedTime: vault, LastVerifiedTime: vault, LastReconciledTime: vault}
+	changed := before
+	changed.LastModifiedTime++
+	verified := before
+	verified.LastVerifiedTime++
+	failed := before
+	failed.Status = CPMStatusFailure
+
+	tests := []struct {
+		name    string
+		op      CPMOperation
+		before  *SecretManagement
+		first   SecretManagement
+		then    SecretManagement
+		wantErr error
+	}{
+		{"change", CPMChange, &before, before, changed, nil},
+		{"verify", CPMVerify, &before, verified, verified, nil},
+		{"first poll as before", CPMChange, nil, before, changed, nil},
+		{"failure", CPMChange, &before, before, failed, ErrCPMOperationFailed},
+		{"other timestamp", CPMVerify, &before, changed, changed, context.DeadlineExceeded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := cpmServer(t, tt.first, tt.then)
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			_, err := client.WaitForCPMOperation(ctx, "1", tt.op, tt.before, time.Millisecond)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("WaitForCPMOperation() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package pam
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// defaultBulkConcurrency is how many requests bulk operations run at once by default
+const defaultBulkConcurrency = 4
+
+// DeleteAccountResult is the outcome of deleting one account in a bulk delete
+type DeleteAccountResult struct {
+	AccountID  string
+	StatusCode int
+	Err        error
+}
+
+// DeleteAccount deletes the account; it returns http.StatusOK on success
+func (c *Client) DeleteAccount(ctx context.Context, acctid string) (int, error) {
+	// DELETE /PasswordVault/API/Accounts/{id}/
+	apiurl := c.apiURL(nil, "Accounts", acctid)
+
+	req, err := newJSONRequest(ctx, http.MethodDelete, apiurl, "delete account", nil)
+	if err != nil {
+		return http.StatusConflict, err
+	}
+
+	status, err := c.do(req, "delete account", nil)
+	if err != nil {
+		return status, err
+	}
+	return http.StatusOK, nil
+}
+
+// DeleteAccounts deletes the accounts, running at most concurrency deletes at
+// once (default 4); results are in the same order as acctids
+func (c *Client) DeleteAccounts(ctx context.Context, acctids []string, concurrency int) []DeleteAccountResult {
+	if concurrency <= 0 {
+		concurrency = defaultBulkConcurrency
+	}
+
+	results := make([]DeleteAccountResult, len(acctids))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(concurrency, len(acctids)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				status, err := c.DeleteAccount(ctx, acctids[i])
+				results[i] = DeleteAccountResult{AccountID: acctids[i], StatusCode: status, Err: err}
+			}
+		}()
+	}
+	for i := range acctids {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return results
+}
+
+// DeleteAccountsFromPager deletes every account the pager returns, Ex: from
+// ListAccountsPager with a SafeName filter.  The error is from the pager, if
+// it stopped early; results cover the accounts read before it stopped.
+func (c *Client) DeleteAccountsFromPager(ctx context.Context, pager *Pager[GetAccountResponse], concurrency int) ([]DeleteAccountResult, error) {
+	// collect the ids first, deleting while paging would shift the offsets
+	acctids := []string{}
+	for pager.Next() {
+		acctids = append(acctids, pager.Item().ID)
+	}
+	return c.DeleteAccounts(ctx, acctids, concurrency), pager.Err()
+}
@@ -0,0 +1,131 @@
+package pam
+
+import (
+	"context"
+	"net/http"
+)
+
+// PostAddAccountGroupRequest is used to create an account group, whose
+// members' secrets the CPM changes together
+type PostAddAccountGroupRequest struct {
+	GroupName       string `json:"GroupName"`       // Required
+	GroupPlatformID string `json:"GroupPlatformID"` // Required, a group platform
+	Safe            string `json:"Safe"`            // Required
+}
+
+// AccountGroup is an account group, as created or listed
+type AccountGroup struct {
+	GroupID         string `json:"GroupID,omitempty"`
+	GroupName       string `json:"GroupName,omitempty"`
+	GroupPlatformID string `json:"GroupPlatformID,omitempty"`
+	Safe            string `json:"Safe,omitempty"`
+}
+
+// AccountGroupMember is an account in an account group
+type AccountGroupMember struct {
+	AccountID  string `json:"AccountID,omitempty"`
+	SafeName   string `json:"SafeName,omitempty"`
+	PlatformID string `json:"PlatformID,omitempty"`
+	Address    string `json:"Address,omitempty"`
+	UserName   string `json:"UserName,omitempty"`
+}
+
+type postAddAccountGroupMemberRequest struct {
+	AccountID string `json:"AccountID"`
+}
+
+// AddAccountGroup creates an account group in a safe
+// REF: <https://docs.cyberark.com/privilege-cloud-shared-services/latest/en/content/webservices/add-account-group.htm>
+func (c *Client) AddAccountGroup(ctx context.Context, groupreq PostAddAccountGroupRequest) (AccountGroup, int, error) {
+	group := AccountGroup{}
+
+	// POST /PasswordVault/API/AccountGroups/
+	apiurl := c.apiURL(nil, "AccountGroups", "")
+
+	req, err := newJSONRequest(ctx, http.MethodPost, apiurl, "add account group", groupreq)
+	if err != nil {
+		return group, http.StatusConflict, err
+	}
+
+	status, err := c.do(req, "add account group", &group)
+	if err != nil {
+		return group, status, err
+	}
+
+	return group, http.StatusOK, nil
+}
+
+// GetAccountGroups returns the account groups in a safe
+func (c *Client) GetAccountGroups(ctx context.Context, safename string) ([]AccountGroup, int, error) {
+	groups := []AccountGroup{}
+
+	// GET /PasswordVault/API/AccountGroups?Safe={safeName}
+	apiurl := c.apiURL(map[string]string{"Safe": safename}, "AccountGroups")
+
+	req, err := newJSONRequest(ctx, http.MethodGet, apiurl, "get account groups", nil)
+	if err != nil {
+		return groups, http.StatusConflict, err
+	}
+
+	status, err := c.do(req, "get account groups", &groups)
+	if err != nil {
+		return groups, status, err
+	}
+
+	return groups, http.StatusOK, nil
+}
+
+// GetAccountGroupMembers returns the accounts in an account group
+func (c *Client) GetAccountGroupMembers(ctx context.Context, groupid string) ([]AccountGroupMember, int, error) {
+	members := []AccountGroupMember{}
+
+	// GET /PasswordVault/API/AccountGroups/{groupId}/Members/
+	apiurl := c.apiURL(nil, "AccountGroups", groupid, "Members")
+
+	req, err := newJSONRequest(ctx, http.MethodGet, apiurl, "get account group members", nil)
+	if err != nil {
+		return members, http.StatusConflict, err
+	}
+
+	status, err := c.do(req, "get account group members", &members)
+	if err != nil {
+		return members, status, err
+	}
+
+	return members, http.StatusOK, nil
+}
+
+// AddAccountGroupMember adds an account to an account group; the account
+// must be in the group's safe
+func (c *Client) AddAccountGroupMember(ctx context.Context, groupid string, acctid string) (int, error) {
+	// POST /PasswordVault/API/AccountGroups/{groupId}/Members/
+	apiurl := c.apiURL(nil, "AccountGroups", groupid, "Members", "")
+
+	req, err := newJSONRequest(ctx, http.MethodPost, apiurl, "add account group member", postAddAccountGroupMemberRequest{AccountID: acctid})
+	if err != nil {
+		return http.StatusConflict, err
+	}
+
+	status, err := c.do(req, "add account group member", nil)
+	if err != nil {
+		return status, err
+	}
+	return http.StatusOK, nil
+}
+
+// RemoveAccountGroupMember removes an account from an account group
+func (c *Client) RemoveAccountGroupMember(ctx context.Context, groupid string, acctid string) (int, error) {
+	// DELETE /PasswordVault/API/AccountGroups/{groupId}/Members/{accountId}/
+	apiurl := c.apiURL(nil, "AccountGroups", groupid, "Members", acctid)
+
+	req, err := newJSONRequest(ctx, http.MethodDelete, apiurl, "remove account group member", nil)
+	if err != nil {
+		return http.StatusConflict, err
+	}
+
+	status, err := c.do(req, "remove account group member", nil)
+	if err != nil {
+		return status, err
+	}
+	return http.StatusOK, nil
+}
@@ -0,0 +1,102 @@
+package pam
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+)
+
+// extraPasswordIndex values for LinkAccount and UnlinkAccount
+const (
+	LinkedLogonAccount     = 1
+	LinkedEnableAccount    = 2
+	LinkedReconcileAccount = 3
+)
+
+// linkedAccountNames are the platform LinkedAccounts names for each extraPasswordIndex
+var linkedAccountNames = map[int]string{
+	LinkedLogonAccount:     "LogonAccount",
+	LinkedEnableAccount:    "EnableAccount",
+	LinkedReconcileAccount: "ReconcileAccount",
+}
+
+type linkAccountRequest struct {
+	Safe               string `json:"safe"`
+	ExtraPasswordIndex int    `json:"extraPasswordIndex"`
+	Name               string `json:"name"`
+	Folder             string `json:"folder"`
+}
+
+// LinkAccount links the account named linkedName in linkedSafe (folder
+// default "Root") to the account as its logon, enable or reconcile account.
+// The account's platform must declare that linked account in its
+// LinkedAccounts, and a platform that GetPlatforms does not return is an error.
+// REF: <https://docs.cyberark.com/privilege-cloud-shared-services/latest/en/content/webservices/link-account.htm>
+func (c *Client) LinkAccount(ctx context.Context, acctid string, extraPassIndex int, linkedSafe, linkedFolder, linkedName string) (int, error) {
+	if status, err := c.validateLinkedAccount(ctx, acctid, extraPassIndex); err != nil {
+		return status, err
+	}
+	if linkedFolder == "" {
+		linkedFolder = "Root"
+	}
+
+	body := linkAccountRequest{
+		Safe:               linkedSafe,
+		ExtraPasswordIndex: extraPassIndex,
+		Name:               linkedName,
+		Folder:             linkedFolder,
+	}
+	return c.accountAction(ctx, acctid, "link account", body, "LinkAccount")
+}
+
+// UnlinkAccount removes the logon, enable or reconcile account linked to the account
+func (c *Client) UnlinkAccount(ctx context.Context, acctid string, extraPassIndex int) (int, error) {
+	if _, ok := linkedAccountNames[extraPassIndex]; !ok {
+		return http.StatusBadRequest, fmt.Errorf("invalid extraPasswordIndex: %d, must be %d, %d or %d", extraPassIndex, LinkedLogonAccount, LinkedEnableAccount, LinkedReconcileAccount)
+	}
+
+	// DELETE /PasswordVault/API/Accounts/{id}/LinkAccount/{extraPasswordIndex}/
+	apiurl := c.apiURL(nil, "Accounts", acctid, "LinkAccount", strconv.Itoa(extraPassIndex))
+
+	req, err := newJSONRequest(ctx, http.MethodDelete, apiurl, "unlink account", nil)
+	if err != nil {
+		return http.StatusConflict, err
+	}
+
+	status, err := c.do(req, "unlink account", nil)
+	if err != nil {
+		return status, err
+	}
+	return http.StatusOK, nil
+}
+
+// validateLinkedAccount checks the account's platform declares the linked account for extraPassIndex
+func (c *Client) validateLinkedAccount(ctx context.Context, acctid string, extraPassIndex int) (int, error) {
+	name, ok := linkedAccountNames[extraPassIndex]
+	if !ok {
+		return http.StatusBadRequest, fmt.Errorf("invalid extraPasswordIndex: %d, must be %d, %d or %d", extraPassIndex, LinkedLogonAccount, LinkedEnableAccount, LinkedReconcileAccount)
+	}
+
+	acct, status, err := c.GetAccountWithContext(ctx, acctid)
+	if err != nil {
+		return status, err
+	}
+	platforms, status, err := c.GetPlatformsWithContext(ctx)
+	if err != nil {
+		return status, err
+	}
+
+	i := slices.IndexFunc(platforms.Platforms, func(p Platform) bool {
+		return p.General.ID == acct.PlatformID
+	})
+	if i < 0 {
+		return http.StatusBadRequest, fmt.Errorf("platform %s of account %s not found, cannot check its linked accounts", acct.PlatformID, acctid)
+	}
+	declared := platforms.Platforms[i].LinkedAccounts
+	if !slices.ContainsFunc(declared, func(la LinkedAccounts) bool { return la.Name == name }) {
+		return http.StatusBadRequest, fmt.Errorf("platform %s does not support linked account %s", acct.PlatformID, name)
+	}
+	return http.StatusOK, nil
+}
@@ -0,0 +1,201 @@
+package pam
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// SearchType is how Search is matched against account properties
+type SearchType string
+
+const (
+	SearchContains   SearchType = "contains" // default
+	SearchStartsWith SearchType = "startswith"
+)
+
+func (st SearchType) valid() bool {
+	return st == SearchContains || st == SearchStartsWith
+}
+
+// SavedFilter selects one of the predefined account lists
+type SavedFilter string
+
+const (
+	SavedFilterRegular                SavedFilter = "Regular"
+	SavedFilterRecently               SavedFilter = "Recently"
+	SavedFilterNew                    SavedFilter = "New"
+	SavedFilterLink                   SavedFilter = "Link"
+	SavedFilterDeleted                SavedFilter = "Deleted"
+	SavedFilterPolicyFailures         SavedFilter = "PolicyFailures"
+	SavedFilterAccessedByUsers        SavedFilter = "AccessedByUsers"
+	SavedFilterModifiedByUsers        SavedFilter = "ModifiedByUsers"
+	SavedFilterModifiedByCPM          SavedFilter = "ModifiedByCPM"
+	SavedFilterDisabledPasswordByUser SavedFilter = "DisabledPasswordByUser"
+	SavedFilterDisabledPasswordByCPM  SavedFilter = "DisabledPasswordByCPM"
+	SavedFilterScheduledForChange     SavedFilter = "ScheduledForChange"
+	SavedFilterScheduledForVerify     SavedFilter = "ScheduledForVerify"
+	SavedFilterScheduledForReconcile  SavedFilter = "ScheduledForReconcile"
+	SavedFilterSuccessfullyReconciled SavedFilter = "SuccessfullyReconciled"
+	SavedFilterFailedChange           SavedFilter = "FailedChange"
+	SavedFilterFailedVerify           SavedFilter = "FailedVerify"
+	SavedFilterFailedReconcile        SavedFilter = "FailedReconcile"
+	SavedFilterLockedOrNew            SavedFilter = "LockedOrNew"
+	SavedFilterLocked                 SavedFilter = "Locked"
+	SavedFilterFavorites              SavedFilter = "Favorites"
+	SavedFilterDeleteInsightStatus    SavedFilter = "DeleteInsightStatus"
+)
+
+var savedFilters = []SavedFilter{
+	SavedFilterRegular, SavedFilterRecently, SavedFilterNew, SavedFilterLink, SavedFilterDeleted, SavedFilterPolicyFailures,
+	SavedFilterAccessedByUsers, SavedFilterModifiedByUsers, SavedFilterModifiedByCPM, SavedFilterDisabledPasswordByUser,
+	SavedFilterDisabledPasswordByCPM, SavedFilterScheduledForChange, SavedFilterScheduledForVerify,
+	SavedFilterScheduledForReconcile, SavedFilterSuccessfullyReconciled, SavedFilterFailedChange,
+	SavedFilterFailedVerify, SavedFilterFailedReconcile, SavedFilterLockedOrNew, SavedFilterLocked,
+	SavedFilterFavorites, SavedFilterDeleteInsightStatus,
+}
+
+func (sf SavedFilter) valid() bool {
+	return slices.Contains(savedFilters, sf)
+}
+
+func joinSavedFilters() string {
+	names := make([]string, len(savedFilters))
+	for i, sf := range savedFilters {
+		names[i] = string(sf)
+	}
+	return strings.Join(names, ", ")
+}
+
+// FilterOp compares a filter property with a value
+type FilterOp string
+
+const (
+	Eq  FilterOp = "eq"
+	Gte FilterOp = "gte"
+	Lte FilterOp = "lte"
+)
+
+// AccountFilter is a filter expression for account search, built with
+// SafeName, ModificationTime, SecretModificationTime and And.
+// REF: <https://docs.cyberark.com/privilege-cloud-shared-services/latest/en/content/sdk/getaccounts.htm#Filterparameters>
+type AccountFilter struct {
+	expr string
+	err  error
+}
+
+// SafeName filters on the account's safe; only Eq is supported
+func SafeName(op FilterOp, name string) AccountFilter {
+	if op != Eq {
+		return AccountFilter{err: fmt.Errorf("invalid filter: safeName supports only %s, got %s", Eq, op)}
+	}
+	return AccountFilter{expr: fmt.Sprintf("safeName %s %s", op, name)}
+}
+
+// ModificationTime filters on when the account was last modified
+func ModificationTime(op FilterOp, t time.Time) AccountFilter {
+	return timeFilter("modificationTime", op, t)
+}
+
+// SecretModificationTime filters on when the account's secret was last modified
+func SecretModificationTime(op FilterOp, t time.Time) AccountFilter {
+	return timeFilter("secretModificationTime", op, t)
+}
+
+func timeFilter(property string, op FilterOp, t time.Time) AccountFilter {
+	if op != Gte && op != Lte {
+		return AccountFilter{err: fmt.Errorf("invalid filter: %s supports only %s or %s, got %s", property, Gte, Lte, op)}
+	}
+	return AccountFilter{expr: fmt.Sprintf("%s %s %d", property, op, t.Unix())}
+}
+
+// And matches accounts that match all filters
+func And(filters ...AccountFilter) AccountFilter {
+	exprs := make([]string, 0, len(filters))
+	for _, f := range filters {
+		if f.err != nil {
+			return f
+		}
+		if f.expr != "" {
+			exprs = append(exprs, f.expr)
+		}
+	}
+	return AccountFilter{expr: strings.Join(exprs, " AND ")}
+}
+
+// String returns the filter expression as sent to the API
+func (f AccountFilter) String() string {
+	return f.expr
+}
+
+// Err returns the error from building the filter, if any
+func (f AccountFilter) Err() error {
+	return f.err
+}
+
+// ListAccountsOptions selects accounts for ListAccounts; zero values are not sent
+type ListAccountsOptions struct {
+	Search      string
+	SearchType  SearchType
+	Sort        []Sort // at most 3 properties
+	Filter      AccountFilter
+	SavedFilter SavedFilter
+	Offset      int
+	Limit       int // 1 - 1000, API default is 50
+}
+
+// query checks o and returns its ListAccounts query parameters
+func (o ListAccountsOptions) query() (map[string]string, error) {
+	qpathparts := map[string]string{}
+	if o.Search != "" {
+		qpathparts["search"] = o.Search
+	}
+	if o.SearchType != "" {
+		if !o.SearchType.valid() {
+			return nil, fmt.Errorf("invalid searchType: %s, must be '%s' or '%s'", o.SearchType, SearchContains, SearchStartsWith)
+		}
+		qpathparts["searchType"] = string(o.SearchType)
+	}
+	if o.Filter.err != nil {
+		return nil, o.Filter.err
+	}
+	if o.Filter.expr != "" {
+		qpathparts["filter"] = o.Filter.expr
+	}
+	if o.SavedFilter != "" {
+		if !o.SavedFilter.valid() {
+			return nil, fmt.Errorf("invalid savedfilter: %s, must be one of: %s", o.SavedFilter, joinSavedFilters())
+		}
+		qpathparts["savedfilter"] = string(o.SavedFilter)
+	}
+	if err := addPageQuery(qpathparts, o.Sort, o.Offset, o.Limit); err != nil {
+		return nil, err
+	}
+	return qpathparts, nil
+}
+
+// ListAccounts returns one page of accounts selected by opts
+func (c *Client) ListAccounts(ctx context.Context, opts ListAccountsOptions) (*GetAccountsResponse, int, error) {
+	qpathparts, err := opts.query()
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	return c.getAccounts(ctx, qpathparts)
+}
+
+// ListAccountsPager walks every account selected by opts, starting at
+// opts.Offset and fetching opts.Limit accounts per request (default 100)
+func (c *Client) ListAccountsPager(ctx context.Context, opts ListAccountsOptions) *Pager[GetAccountResponse] {
+	return newPager(ctx, opts.Offset, opts.Limit, func(ctx context.Context, offset, limit int) ([]GetAccountResponse, int, bool, error) {
+		page := opts
+		page.Offset, page.Limit = offset, limit
+		resp, _, err := c.ListAccounts(ctx, page)
+		if err != nil {
+			return nil, 0, false, err
+		}
+		return resp.Value, resp.Count, resp.NextLink != "", nil
+	})
+}
@@ -0,0 +1,127 @@
+package pam
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// PatchOp is one JSON Patch operation for UpdateAccount
+// REF: <https://docs.cyberark.com/privilege-cloud-shared-services/latest/en/content/webservices/update%20account.htm>
+type PatchOp struct {
+	Op    string `json:"op"` // add, replace or remove
+	Path  string `json:"path"`
+	Value any    `json:"value"`
+}
+
+// Add adds value at path, Ex: Add("/platformAccountProperties/Port", "2222")
+func Add(path string, value any) PatchOp {
+	return PatchOp{Op: "add", Path: path, Value: value}
+}
+
+// Replace sets the value at path, Ex: Replace("/address", "10.0.0.1")
+func Replace(path string, value any) PatchOp {
+	return PatchOp{Op: "replace", Path: path, Value: value}
+}
+
+// Remove deletes the value at path
+func Remove(path string) PatchOp {
+	return PatchOp{Op: "remove", Path: path}
+}
+
+// MarshalJSON leaves out value for remove, and keeps empty values for add and replace
+func (op PatchOp) MarshalJSON() ([]byte, error) {
+	if op.Op == "remove" {
+		return json.Marshal(struct {
+			Op   string `json:"op"`
+			Path string `json:"path"`
+		}{op.Op, op.Path})
+	}
+	type patchop PatchOp // no MarshalJSON method
+	return json.Marshal(patchop(op))
+}
+
+// escapePointer escapes a key for use in a JSON Pointer path
+func escapePointer(key string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+}
+
+// DiffAccounts returns the patch that changes the updatable properties of
+// from into those of to.  Properties that cannot be patched, such as
+// safeName and secretType, are ignored.
+func DiffAccounts(from, to GetAccountResponse) []PatchOp {
+	ops := []PatchOp{}
+
+	replaceString := func(path, a, b string) {
+		if a != b {
+			ops = append(ops, Replace(path, b))
+		}
+	}
+	replaceString("/name", from.Name, to.Name)
+	replaceString("/address", from.Address, to.Address)
+	replaceString("/userName", from.UserName, to.UserName)
+	replaceString("/platformId", from.PlatformID, to.PlatformID)
+
+	// platformAccountProperties in key order, so the patch is stable
+	keys := make([]string, 0, len(from.PlatformAccountProperties)+len(to.PlatformAccountProperties))
+	for key := range from.PlatformAccountProperties {
+		keys = append(keys, key)
+	}
+	for key := range to.PlatformAccountProperties {
+		if _, ok := from.PlatformAccountProperties[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		path := "/platformAccountProperties/" + escapePointer(key)
+		oldvalue, inold := from.PlatformAccountProperties[key]
+		newvalue, innew := to.PlatformAccountProperties[key]
+		switch {
+		case inold && !innew:
+			ops = append(ops, Remove(path))
+		case !inold && innew:
+			ops = append(ops, Add(path, newvalue))
+		case oldvalue != newvalue:
+			ops = append(ops, Replace(path, newvalue))
+		}
+	}
+
+	replaceString("/remoteMachinesAccess/remoteMachines", from.RemoteMachinesAccess.RemoteMachines, to.RemoteMachinesAccess.RemoteMachines)
+	if from.RemoteMachinesAccess.AccessRestrictedToRemoteMachines != to.RemoteMachinesAccess.AccessRestrictedToRemoteMachines {
+		ops = append(ops, Replace("/remoteMachinesAccess/accessRestrictedToRemoteMachines", to.RemoteMachinesAccess.AccessRestrictedToRemoteMachines))
+	}
+
+	if from.SecretManagement.AutomaticManagementEnabled != to.SecretManagement.AutomaticManagementEnabled {
+		ops = append(ops, Replace("/secretManagement/automaticManagementEnabled", to.SecretManagement.AutomaticManagementEnabled))
+	}
+	replaceString("/secretManagement/manualManagementReason", from.SecretManagement.ManualManagementReason, to.SecretManagement.ManualManagementReason)
+
+	return ops
+}
+
+// UpdateAccount applies the JSON Patch ops to the account and returns the updated account
+func (c *Client) UpdateAccount(ctx context.Context, acctid string, ops []PatchOp) (GetAccountResponse, int, error) {
+	accountresp := GetAccountResponse{}
+	if len(ops) == 0 {
+		return accountresp, http.StatusBadRequest, fmt.Errorf("update account %s: no patch operations", acctid)
+	}
+
+	// PATCH /PasswordVault/API/Accounts/{id}/
+	apiurl := c.apiURL(nil, "Accounts", acctid)
+
+	req, err := newJSONRequest(ctx, http.MethodPatch, apiurl, "update account", ops)
+	if err != nil {
+		return accountresp, http.StatusConflict, err
+	}
+
+	status, err := c.do(req, "update account", &accountresp)
+	if err != nil {
+		return accountresp, status, err
+	}
+
+	return accountresp, http.StatusOK, nil
+}
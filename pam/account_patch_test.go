@@ -0,0 +1,63 @@
+package pam
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDiffAccounts(t *testing.T) {
+	from := GetAccountResponse{
+		Address: "10.0.0.1",
+		PlatformAccountProperties: map[string]string{
+			"Port":     "22",
+			"Location": "dc1",
+			"a/b~c":    "old",
+		},
+	}
+	to := GetAccountResponse{
+		Address: "10.0.0.2",
+		PlatformAccountProperties: map[string]string{
+			"Port":  "2222",
+			"Zone":  "east",
+			"a/b~c": "new",
+		},
+	}
+
+	b, err := json.Marshal(DiffAccounts(from, to))
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	want := `[{"op":"replace","path":"/address","value":"10.0.0.2"},` +
+		`{"op":"remove","path":"/platformAccountProperties/Location"},` +
+		`{"op":"replace","path":"/platformAccountProperties/Port","value":"2222"},` +
+		`{"op":"add","path":"/platformAccountProperties/Zone","value":"east"},` +
+		`{"op":"replace","path":"/platformAccountProperties/a~1b~0c","value":"new"}]`
+	if string(b) != want {
+		t.Errorf("DiffAccounts() =\n%s\nwant\n%s", b, want)
+	}
+
+	if ops := DiffAccounts(from, from); len(ops) != 0 {
+		t.Errorf("DiffAccounts() of equal accounts = %v, want none", ops)
+	}
+}
+
+func TestPatchOpMarshalJSON(t *testing.T) {
+	tests := []struct {
+		op   PatchOp
+		want string
+	}{
+		{Remove("/platformAccountProperties/Port"), `{"op":"remove","path":"/platformAccountProperties/Port"}`},
+		{Replace("/secretManagement/manualManagementReason", ""), `{"op":"replace","path":"/secretManagement/manualManagementReason","value":""}`},
+		{Add("/platformAccountProperties/Port", "2222"), `{"op":"add","path":"/platformAccountProperties/Port","value":"2222"}`},
+		{Replace("/secretManagement/automaticManagementEnabled", false), `{"op":"replace","path":"/secretManagement/automaticManagementEnabled","value":false}`},
+	}
+	for _, tt := range tests {
+		b, err := json.Marshal(tt.op)
+		if err != nil {
+			t.Fatalf("json.Marshal(%v) error = %v", tt.op, err)
+		}
+		if string(b) != tt.want {
+			t.Errorf("json.Marshal(%v) = %s, want %s", tt.op, b, tt.want)
+		}
+	}
+}
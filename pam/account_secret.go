@@ -0,0 +1,188 @@
+package pam
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// RetrieveSecretOptions are the request details for RetrieveSecret; which
+// are required depends on the platform's access workflows
+// REF: <https://docs.cyberark.com/privilege-cloud-shared-services/latest/en/content/webservices/getpasswordvalueV10.htm>
+type RetrieveSecretOptions struct {
+	Reason              string `json:"reason,omitempty"`
+	TicketingSystemName string `json:"TicketingSystemName,omitempty"`
+	TicketID            string `json:"TicketId,omitempty"`
+	Version             int    `json:"Version,omitempty"`    // 0 means the current version
+	ActionType          string `json:"ActionType,omitempty"` // Ex: "show", "copy", "connect"
+	IsUse               bool   `json:"isUse,omitempty"`
+	Machine             string `json:"Machine,omitempty"`
+}
+
+// redacted is what a Secret prints as
+const redacted = "[REDACTED]"
+
+// Secret holds a retrieved credential.  It never prints its value with fmt
+// or slog, whether held as a value or a pointer, use Bytes to read it, and
+// Close to zero it once done.
+type Secret struct {
+	value []byte
+}
+
+// Bytes returns the secret value; the slice is zeroed by Close
+func (s *Secret) Bytes() []byte {
+	return s.value
+}
+
+// Close zeroes the secret value
+func (s *Secret) Close() error {
+	clear(s.value)
+	s.value = nil
+	return nil
+}
+
+func (s Secret) String() string {
+	return redacted
+}
+
+func (s Secret) GoString() string {
+	return "pam.Secret{" + redacted + "}"
+}
+
+// Format prints the secret as [REDACTED] for every verb
+func (s Secret) Format(f fmt.State, verb rune) {
+	if verb == 'v' && f.Flag('#') {
+		fmt.Fprint(f, s.GoString())
+		return
+	}
+	fmt.Fprint(f, redacted)
+}
+
+func (s Secret) LogValue() slog.Value {
+	return slog.StringValue(redacted)
+}
+
+// MarshalJSON encodes the secret as [REDACTED], so it cannot leak into logged structs
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(redacted)), nil
+}
+
+// RetrieveSecret returns the account's secret; Close the secret when done
+func (c *Client) RetrieveSecret(ctx context.Context, acctid string, opts RetrieveSecretOptions) (*Secret, int, error) {
+	// POST /PasswordVault/API/Accounts/{id}/Password/Retrieve/
+	apiurl := c.apiURL(nil, "Accounts", acctid, "Password", "Retrieve")
+
+	req, err := newJSONRequest(ctx, http.MethodPost, apiurl, "retrieve secret", opts)
+	if err != nil {
+		return nil, http.StatusConflict, err
+	}
+
+	body, status, err := c.doRaw(req, "retrieve secret")
+	defer clear(body)
+	if err != nil {
+		return nil, status, err
+	}
+
+	value, err := decodeJSONString(bytes.TrimSpace(body))
+	if err != nil {
+		// the body is not included, it may hold the secret
+		return nil, status, newRequestError(ErrDecodeResponse, "retrieve secret", err)
+	}
+	return &Secret{value: value}, http.StatusOK, nil
+}
+
+// decodeJSONString decodes a json string into a new byte slice, without the
+// intermediate Go string json.Unmarshal would leave behind
+func decodeJSONString(data []byte) ([]byte, error) {
+	if len(data) < 2 || data[0] != '"' || data[len(data)-1] != '"' {
+		return nil, errors.New("expected a json string")
+	}
+	data = data[1 : len(data)-1]
+
+	value := make([]byte, 0, len(data))
+	for i := 0; i < len(data); i++ {
+		ch := data[i]
+		if ch != '\\' {
+			value = append(value, ch)
+			continue
+		}
+		i++
+		if i >= len(data) {
+			clear(value)
+			return nil, errors.New("unterminated escape in json string")
+		}
+		switch data[i] {
+		case '"', '\\', '/':
+			value = append(value, data[i])
+		case 'b':
+			value = append(value, '\b')
+		case 'f':
+			value = append(value, '\f')
+		case 'n':
+			value = append(value, '\n')
+		case 'r':
+			value = append(value, '\r')
+		case 't':
+			value = append(value, '\t')
+		case 'u':
+			r, n := decodeJSONRune(data[i+1:])
+			if n == 0 {
+				clear(value)
+				return nil, errors.New("invalid \\u escape in json string")
+			}
+			value = utf8.AppendRune(value, r)
+			i += n
+		default:
+			clear(value)
+			return nil, errors.New("invalid escape in json string")
+		}
+	}
+	return value, nil
+}
+
+// decodeJSONRune decodes the hex digits after \u, and a following \u low
+// surrogate if needed; it returns the rune and the bytes consumed
+func decodeJSONRune(data []byte) (rune, int) {
+	r, ok := hex4(data)
+	if !ok {
+		return 0, 0
+	}
+	if !utf16.IsSurrogate(r) {
+		return r, 4
+	}
+	if len(data) >= 10 && data[4] == '\\' && data[5] == 'u' {
+		if low, ok := hex4(data[6:]); ok {
+			if pair := utf16.DecodeRune(r, low); pair != utf8.RuneError {
+				return pair, 10
+			}
+		}
+	}
+	return utf8.RuneError, 4
+}
+
+func hex4(data []byte) (rune, bool) {
+	if len(data) < 4 {
+		return 0, false
+	}
+	var r rune
+	for _, ch := range data[:4] {
+		switch {
+		case '0' <= ch && ch <= '9':
+			ch -= '0'
+		case 'a' <= ch && ch <= 'f':
+			ch = ch - 'a' + 10
+		case 'A' <= ch && ch <= 'F':
+			ch = ch - 'A' + 10
+		default:
+			return 0, false
+		}
+		r = r*16 + rune(ch)
+	}
+	return r, true
+}
@@ -0,0 +1,43 @@
+package pam
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestSecretRedacted(t *testing.T) {
+	s := &Secret{value: []byte("hunter2")}
+	holder := struct {
+		Name    string
+		Value   Secret
+		Pointer *Secret
+	}{"acct", *s, s}
+
+	outputs := map[string]string{}
+	for _, format := range []string{"%v", "%+v", "%#v", "%s", "%q", "%x"} {
+		outputs[format+" pointer"] = fmt.Sprintf(format, s)
+		outputs[format+" value"] = fmt.Sprintf(format, *s)
+		outputs[format+" struct"] = fmt.Sprintf(format, holder)
+	}
+	b, err := json.Marshal(holder)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	outputs["json"] = string(b)
+	var buf bytes.Buffer
+	slog.New(slog.NewTextHandler(&buf, nil)).Info("secret", "value", *s, "pointer", s)
+	outputs["slog"] = buf.String()
+
+	for name, out := range outputs {
+		if strings.Contains(out, "hunter2") || strings.Contains(out, fmt.Sprintf("%x", "hunter2")) {
+			t.Errorf("%s leaked the secret: %s", name, out)
+		}
+	}
+	if got := string(s.Bytes()); got != "hunter2" {
+		t.Errorf("Bytes() = %q, want %q", got, "hunter2")
+	}
+}
@@ -0,0 +1,56 @@
+package pam
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// SecretTypeKey is the PostAddAccountRequest.SecretType for SSH key accounts
+const SecretTypeKey = "key"
+
+// maxSSHKeySize bounds how much of the reader NewSSHKeyAccountRequest reads
+const maxSSHKeySize = 64 * 1024
+
+// NewSSHKeyAccountRequest returns a copy of acct that onboards the PEM
+// private key read from privatekey (PKCS#1, PKCS#8, EC or OpenSSH format) as
+// its secret, along with the key's SHA256 public key fingerprint.
+// Passphrase protected keys are rejected, the CPM cannot use them.
+func NewSSHKeyAccountRequest(acct PostAddAccountRequest, privatekey io.Reader) (PostAddAccountRequest, string, error) {
+	pemdata, err := io.ReadAll(io.LimitReader(privatekey, maxSSHKeySize+1))
+	if err != nil {
+		return acct, "", fmt.Errorf("failed to read ssh private key: %w", err)
+	}
+	if len(pemdata) > maxSSHKeySize {
+		return acct, "", fmt.Errorf("ssh private key is larger than %d bytes", maxSSHKeySize)
+	}
+
+	signer, err := ssh.ParsePrivateKey(pemdata)
+	if err != nil {
+		var missing *ssh.PassphraseMissingError
+		if errors.As(err, &missing) {
+			return acct, "", errors.New("ssh private key is passphrase protected, provide an unencrypted key")
+		}
+		return acct, "", fmt.Errorf("invalid ssh private key: %w", err)
+	}
+
+	acct.SecretType = SecretTypeKey
+	acct.Secret = string(pemdata)
+	return acct, ssh.FingerprintSHA256(signer.PublicKey()), nil
+}
+
+// AddSSHKeyAccount validates the PEM private key read from privatekey and
+// adds acct with it as an SSH key secret, see NewSSHKeyAccountRequest.  It
+// returns the new account and the key's SHA256 public key fingerprint.
+func (c *Client) AddSSHKeyAccount(ctx context.Context, acct PostAddAccountRequest, privatekey io.Reader) (PostAddAccountResponse, string, int, error) {
+	accountreq, fingerprint, err := NewSSHKeyAccountRequest(acct, privatekey)
+	if err != nil {
+		return PostAddAccountResponse{}, "", http.StatusBadRequest, err
+	}
+	newacct, status, err := c.AddAccountWithContext(ctx, accountreq)
+	return newacct, fingerprint, status, err
+}
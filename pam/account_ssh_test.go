@@ -0,0 +1,64 @@
+package pam
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestNewSSHKeyAccountRequest(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("ed25519.GenerateKey() error = %v", err)
+	}
+	block, err := ssh.MarshalPrivateKey(priv, "")
+	if err != nil {
+		t.Fatalf("ssh.MarshalPrivateKey() error = %v", err)
+	}
+	keypem := pem.EncodeToMemory(block)
+	sshpub, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatalf("ssh.NewPublicKey() error = %v", err)
+	}
+
+	acct, fingerprint, err := NewSSHKeyAccountRequest(PostAddAccountRequest{SafeName: "safe1"}, bytes.NewReader(keypem))
+	if err != nil {
+		t.Fatalf("NewSSHKeyAccountRequest() error = %v", err)
+	}
+	if want := ssh.FingerprintSHA256(sshpub); fingerprint != want {
+		t.Errorf("fingerprint = %s, want %s", fingerprint, want)
+	}
+	if acct.SecretType != SecretTypeKey || acct.Secret != string(keypem) || acct.SafeName != "safe1" {
+		t.Errorf("NewSSHKeyAccountRequest() = %+v, want the key as a %q secret in safe1", acct, SecretTypeKey)
+	}
+
+	encrypted, err := ssh.MarshalPrivateKeyWithPassphrase(priv, "", []byte("passphrase"))
+	if err != nil {
+		t.Fatalf("ssh.MarshalPrivateKeyWithPassphrase() error = %v", err)
+	}
+	tests := []struct {
+		name    string
+		input   []byte
+		wantErr string
+	}{
+		{"encrypted", pem.EncodeToMemory(encrypted), "passphrase protected"},
+		{"not pem", []byte("not a key"), "invalid ssh private key"},
+		{"too large", bytes.Repeat([]byte("a"), maxSSHKeySize+1), "larger than"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, fingerprint, err := NewSSHKeyAccountRequest(PostAddAccountRequest{}, bytes.NewReader(tt.input))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("NewSSHKeyAccountRequest() error = %v, want %q", err, tt.wantErr)
+			}
+			if fingerprint != "" {
+				t.Errorf("fingerprint = %q, want none", fingerprint)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package pam
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// SecretVersion describes one stored version of an account's secret
+type SecretVersion struct {
+	VersionID        int    `json:"versionID"`
+	ModifiedBy       string `json:"modifiedBy,omitempty"`
+	ModificationDate int64  `json:"modificationDate,omitempty"` // epoch seconds
+	IsTemporary      bool   `json:"isTemporary,omitempty"`
+}
+
+// ModificationTime returns when the version was created
+func (v SecretVersion) ModificationTime() time.Time {
+	return time.Unix(v.ModificationDate, 0)
+}
+
+type GetSecretVersionsResponse struct {
+	Versions []SecretVersion `json:"versions,omitempty"`
+}
+
+// GetSecretVersions returns the metadata of the account's stored secret
+// versions; how many are kept is set by the safe's NumberOfVersionsRetention
+// REF: <https://docs.cyberark.com/privilege-cloud-shared-services/latest/en/content/webservices/secret-versions.htm>
+func (c *Client) GetSecretVersions(ctx context.Context, acctid string) (GetSecretVersionsResponse, int, error) {
+	versions := GetSecretVersionsResponse{}
+
+	// GET /PasswordVault/API/Accounts/{id}/Secret/Versions/
+	apiurl := c.apiURL(nil, "Accounts", acctid, "Secret", "Versions")
+
+	req, err := newJSONRequest(ctx, http.MethodGet, apiurl, "get secret versions", nil)
+	if err != nil {
+		return versions, http.StatusConflict, err
+	}
+
+	status, err := c.do(req, "get secret versions", &versions)
+	if err != nil {
+		return versions, status, err
+	}
+
+	return versions, http.StatusOK, nil
+}
+
+// RetrieveSecretVersion returns a specific version of the account's secret,
+// Ex: a VersionID from GetSecretVersions to roll back a bad rotation with
+// ChangeCredentialsInVault; Close the secret when done
+func (c *Client) RetrieveSecretVersion(ctx context.Context, acctid string, versionid int, opts RetrieveSecretOptions) (*Secret, int, error) {
+	opts.Version = versionid
+	return c.RetrieveSecret(ctx, acctid, opts)
+}
@@ -2,8 +2,11 @@ package pam
 
 import (
 	"crypto/tls"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"sync"
 	"time"
 )
 
@@ -13,10 +16,18 @@ const (
 )
 
 type Client struct {
-	BaseURL  string
-	AuthType string
-	Session  *Session
-	Config   *Config
+	BaseURL    string
+	AuthType   string
+	Session    *Session
+	Config     *Config
+	HTTPClient *http.Client // nil means use the shared default client
+
+	RetryPolicy *RetryPolicy // nil means failed requests are not retried
+
+	limiter     *rateLimiter  // see WithRateLimit
+	inflight    chan struct{} // see WithMaxInFlight
+	sessionMu   sync.Mutex    // guards Session and sessionCall
+	sessionCall *sessionCall
 }
 
 type Config struct {
@@ -58,14 +69,173 @@ func DisableTlsVerify() func(*Client) error {
 	}
 }
 
+// WithHTTPClient - use hc for all requests, including the session token request;
+// TlsSkipVerify is ignored, configure TLS on hc's transport instead
+func WithHTTPClient(hc *http.Client) func(*Client) error {
+	return func(c *Client) error {
+		c.HTTPClient = hc
+		return nil
+	}
+}
+
+// WithTransport - use rt as the round tripper, with the default 30s timeout
+func WithTransport(rt http.RoundTripper) func(*Client) error {
+	return func(c *Client) error {
+		c.HTTPClient = &http.Client{
+			Timeout:   time.Second * 30,
+			Transport: rt,
+		}
+		return nil
+	}
+}
+
+// httpClient returns the injected client, or the shared pooled client
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	if c.Config != nil && c.Config.TlsSkipVerify {
+		return sharedInsecureClient()
+	}
+	return sharedClient()
+}
+
+// SendRequest sends req with the session token attached; cancellation and
+// deadlines come from the request's context (see http.NewRequestWithContext).
+// When the client has credentials, a token is requested on first use and
+// renewed shortly before it expires, and a 401 response is retried once with
+// a new token.  Other failures are retried according to the client's
+// RetryPolicy, if set.  Each attempt waits for the client's rate limit and
+// in-flight cap, see WithRateLimit and WithMaxInFlight.
 func (c *Client) SendRequest(req *http.Request) (*http.Response, error) {
-	// if token is provided, add header Authorization
-	if c.Session != nil && c.Session.Token != "" {
-		req.Header.Add("Authorization", fmt.Sprintf("%s %s", c.Session.TokenType, c.Session.Token))
+	policy := c.RetryPolicy
+	if policy == nil || !policy.allows(req) {
+		return c.send(req)
 	}
+	ctx := req.Context()
+
+	for attempt := 1; ; attempt++ {
+		attemptreq := req
+		if attempt > 1 {
+			var err error
+			attemptreq, err = rewindRequest(req)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		res, err := c.send(attemptreq)
+		if attempt >= policy.MaxAttempts || !shouldRetry(ctx, res, err) {
+			return res, err
+		}
+
+		delay := policy.delay(attempt, res)
+		if policy.OnRetry != nil {
+			event := RetryEvent{
+				Attempt: attempt,
+				Method:  req.Method,
+				URL:     req.URL.Redacted(),
+				Err:     err,
+				Delay:   delay,
+			}
+			if res != nil {
+				event.StatusCode = res.StatusCode
+			}
+			policy.OnRetry(event)
+		}
+		if res != nil {
+			io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
 
-	client := GetHTTPClient(time.Second*30, c.Config.TlsSkipVerify)
-	return client.Do(req)
+// send makes one attempt at req, retrying once with a new token on a 401
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	sess, err := c.currentSession(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session token: %w", err)
+	}
+	authorize(req, sess)
+
+	res, err := c.roundTrip(req)
+	if err != nil || res.StatusCode != http.StatusUnauthorized || !c.canRefreshSession() {
+		return res, err
+	}
+
+	retry, err := rewindRequest(req)
+	if err != nil {
+		return res, nil // body cannot be replayed
+	}
+	sess, err = c.currentSession(ctx, sess)
+	if err != nil {
+		return res, nil
+	}
+	io.Copy(io.Discard, res.Body)
+	res.Body.Close()
+	authorize(retry, sess)
+	return c.roundTrip(retry)
+}
+
+// rewindRequest returns a copy of req with a fresh body, so it can be sent again
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	if !canRewind(req) {
+		return nil, errors.New("request body cannot be replayed")
+	}
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		retry.Body = body
+	}
+	return retry, nil
+}
+
+func canRewind(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// authorize sets header Authorization if a token is available
+func authorize(req *http.Request, sess *Session) {
+	if sess != nil && sess.Token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("%s %s", sess.TokenType, sess.Token))
+	}
+}
+
+var (
+	sharedClient = sync.OnceValue(func() *http.Client {
+		return newPooledClient(false)
+	})
+	sharedInsecureClient = sync.OnceValue(func() *http.Client {
+		return newPooledClient(true)
+	})
+)
+
+// newPooledClient create http client with 30s timeout on a pooled, keep-alive
+// transport cloned from http.DefaultTransport (honors HTTP(S)_PROXY)
+func newPooledClient(skipverify bool) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = 16
+	if skipverify {
+		transport.TLSClientConfig = &tls.Config{
+			InsecureSkipVerify: skipverify, /* TLS_SKIP_VERIFY */
+		}
+	}
+	return &http.Client{
+		Timeout:   time.Second * 30,
+		Transport: transport,
+	}
 }
 
 // GetDefaultHTTPClient create http client with 30s timeout and no skip verify
@@ -73,7 +243,8 @@ func GetDefaultHTTPClient() *http.Client {
 	return GetHTTPClient(time.Second*30, false)
 }
 
-// GetHTTPClient create http client for HTTPS
+// GetHTTPClient create http client for HTTPS; each call builds a new transport,
+// so prefer WithHTTPClient or the client's shared default for repeated use
 func GetHTTPClient(timeout time.Duration, skipverify bool) *http.Client {
 	client := &http.Client{
 		Timeout: timeout, /*time.Second * 30 */
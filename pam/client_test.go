@@ -0,0 +1,86 @@
+package pam
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// tokenServer serves platform tokens tok1, tok2, ... and passes other requests to api
+func tokenServer(t *testing.T, tokens *atomic.Int32, api http.HandlerFunc) *Client {
+	t.Helper()
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/oauth2/platformtoken" {
+			n := tokens.Add(1)
+			fmt.Fprintf(w, `{"access_token":"tok%d","token_type":"Bearer","expires_in":900}`, n)
+			return
+		}
+		api(w, r)
+	})
+	return NewClient(srv.URL, NewConfig(srv.URL, srv.URL, "user", "pass"))
+}
+
+func TestSendRequestSingleFlightToken(t *testing.T) {
+	var tokens atomic.Int32
+	client := tokenServer(t, &tokens, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"id":"1"}`)
+	})
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 30)
+	for range 30 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, err := client.GetAccount("1")
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("GetAccount() error = %v", err)
+		}
+	}
+	if n := tokens.Load(); n != 1 {
+		t.Errorf("token requests = %d, want 1", n)
+	}
+}
+
+func TestSendRequestReplaysOn401(t *testing.T) {
+	var tokens, calls atomic.Int32
+	var bodies []string
+	var mu sync.Mutex
+	client := tokenServer(t, &tokens, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, string(body))
+		mu.Unlock()
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintf(w, `{"id":"1","name":%q}`, r.Header.Get("Authorization"))
+	})
+
+	resp, status, err := client.AddAccount(PostAddAccountRequest{SafeName: "safe1", PlatformID: "UnixSSH"})
+	if err != nil {
+		t.Fatalf("AddAccount() error = %v", err)
+	}
+	if status != http.StatusOK || resp.Name != "Bearer tok2" {
+		t.Errorf("AddAccount() = (%d, %q), want (200, Bearer tok2)", status, resp.Name)
+	}
+	if len(bodies) != 2 || bodies[0] != bodies[1] || !strings.Contains(bodies[1], "safe1") {
+		t.Errorf("request bodies = %q, want the same body sent twice", bodies)
+	}
+}
@@ -0,0 +1,152 @@
+package pam
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Kinds of RequestError, use with errors.Is
+var (
+	ErrEncodeRequest  = errors.New("failed to encode request body")
+	ErrBuildRequest   = errors.New("failed to build request")
+	ErrSendRequest    = errors.New("failed to send request")
+	ErrReadResponse   = errors.New("failed to read response body")
+	ErrDecodeResponse = errors.New("failed to parse response body")
+)
+
+// RequestError is returned when a request could not be made or its response
+// could not be read; it is not an error reported by the API
+type RequestError struct {
+	Kind error  // one of the Err* kinds above
+	Op   string // Ex: "add safe"
+	Err  error  // underlying error
+}
+
+func newRequestError(kind error, op string, err error) *RequestError {
+	return &RequestError{Kind: kind, Op: op, Err: err}
+}
+
+func (e *RequestError) Error() string {
+	return fmt.Sprintf("%s: %s: %s", e.Op, e.Kind.Error(), e.Err.Error())
+}
+
+func (e *RequestError) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// CyberArk error codes that mean the object already exists
+var alreadyExistsCodes = []string{
+	"SFWS0002", // safe already exists
+}
+
+// CyberArk error codes that mean the safe cannot be deleted yet because its
+// retention period has not passed
+var safeDeleteBlockedCodes = []string{
+	"ITATS530E", // safe contains objects not yet ready for deletion
+}
+
+// requestIDHeaders are checked in order for a request ID to report in APIError
+var requestIDHeaders = []string{"X-Request-Id", "X-Correlation-Id", "X-Amzn-Requestid"}
+
+// APIError is returned for any non-2xx response from Privilege Cloud or the
+// Identity tenant
+type APIError struct {
+	StatusCode   int
+	ErrorCode    string // Ex: "SFWS0002"
+	ErrorMessage string
+	Method       string
+	URL          string
+	RequestID    string
+	Body         string // raw response body
+}
+
+func newAPIError(res *http.Response, body []byte) *APIError {
+	apierr := APIError{
+		StatusCode: res.StatusCode,
+		Body:       string(body),
+	}
+	if res.Request != nil {
+		apierr.Method = res.Request.Method
+		apierr.URL = res.Request.URL.Redacted()
+	}
+	for _, h := range requestIDHeaders {
+		if id := res.Header.Get(h); id != "" {
+			apierr.RequestID = id
+			break
+		}
+	}
+
+	var errresp ErrorResponse
+	if json.Unmarshal(body, &errresp) == nil {
+		apierr.ErrorCode = errresp.ErrorCode
+		apierr.ErrorMessage = errresp.ErrorMessage
+	}
+	if apierr.ErrorCode == "" {
+		var idresp IDTenantResponse
+		if json.Unmarshal(body, &idresp) == nil {
+			apierr.ErrorCode = idresp.Error
+			apierr.ErrorMessage = idresp.ErrorDescription
+		}
+	}
+	if apierr.ErrorCode == "" && apierr.ErrorMessage == "" {
+		apierr.ErrorMessage = strings.TrimSpace(string(body))
+	}
+	return &apierr
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("%s %s: received non-200 status code(%d)", e.Method, e.URL, e.StatusCode)
+	if e.ErrorCode != "" {
+		msg += ": " + e.ErrorCode
+	}
+	if e.ErrorMessage != "" {
+		msg += ": " + e.ErrorMessage
+	}
+	if e.RequestID != "" {
+		msg += " (request id " + e.RequestID + ")"
+	}
+	return msg
+}
+
+// IsNotFound reports whether err is an APIError for a missing object
+func IsNotFound(err error) bool {
+	var apierr *APIError
+	return errors.As(err, &apierr) && apierr.StatusCode == http.StatusNotFound
+}
+
+// IsAlreadyExists reports whether err is an APIError for an object that already exists
+func IsAlreadyExists(err error) bool {
+	var apierr *APIError
+	if !errors.As(err, &apierr) {
+		return false
+	}
+	if apierr.StatusCode == http.StatusConflict {
+		return true
+	}
+	return slices.ContainsFunc(alreadyExistsCodes, func(code string) bool {
+		return strings.HasPrefix(apierr.ErrorCode, code)
+	})
+}
+
+// isSafeDeleteBlocked reports whether apierr is a safe deletion blocked by retention
+func isSafeDeleteBlocked(apierr *APIError) bool {
+	return slices.ContainsFunc(safeDeleteBlockedCodes, func(code string) bool {
+		return strings.HasPrefix(apierr.ErrorCode, code)
+	})
+}
+
+// IsUnauthorized reports whether err is an APIError for a missing or rejected token
+func IsUnauthorized(err error) bool {
+	var apierr *APIError
+	return errors.As(err, &apierr) && apierr.StatusCode == http.StatusUnauthorized
+}
+
+// IsForbidden reports whether err is an APIError for missing permissions
+func IsForbidden(err error) bool {
+	var apierr *APIError
+	return errors.As(err, &apierr) && apierr.StatusCode == http.StatusForbidden
+}
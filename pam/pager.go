@@ -0,0 +1,174 @@
+package pam
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultPageSize = 100
+	maxPageSize     = 1000
+)
+
+// maxSortProperties is the most properties the API accepts in sort
+const maxSortProperties = 3
+
+// SortDirection is the order of a Sort
+type SortDirection string
+
+const (
+	SortAsc  SortDirection = "asc"
+	SortDesc SortDirection = "desc"
+)
+
+// Sort orders a list by a property, Ex: Desc("userName")
+type Sort struct {
+	Property  string
+	Direction SortDirection
+}
+
+// Asc sorts by property in ascending order
+func Asc(property string) Sort {
+	return Sort{Property: property, Direction: SortAsc}
+}
+
+// Desc sorts by property in descending order
+func Desc(property string) Sort {
+	return Sort{Property: property, Direction: SortDesc}
+}
+
+func (s Sort) String() string {
+	if s.Direction == "" {
+		return s.Property
+	}
+	return fmt.Sprintf("%s %s", s.Property, s.Direction)
+}
+
+// list returns s as the sort list for addPageQuery, empty if s is unset
+func (s Sort) list() []Sort {
+	if s.Property == "" {
+		return nil
+	}
+	return []Sort{s}
+}
+
+// addPageQuery validates the sort, offset and limit shared by the list
+// endpoints and adds those that are set to qpathparts
+func addPageQuery(qpathparts map[string]string, sorts []Sort, offset, limit int) error {
+	if len(sorts) > maxSortProperties {
+		return fmt.Errorf("sort accepts at most %d properties, got %d", maxSortProperties, len(sorts))
+	}
+	if len(sorts) > 0 {
+		props := make([]string, len(sorts))
+		for i, s := range sorts {
+			if s.Property == "" {
+				return fmt.Errorf("sort property is empty")
+			}
+			if s.Direction != "" && s.Direction != SortAsc && s.Direction != SortDesc {
+				return fmt.Errorf("invalid sort direction: %s, must be '%s' or '%s'", s.Direction, SortAsc, SortDesc)
+			}
+			props[i] = s.String()
+		}
+		qpathparts["sort"] = strings.Join(props, ",")
+	}
+	if offset < 0 {
+		return fmt.Errorf("offset must be 0 or greater, got %d", offset)
+	}
+	if offset > 0 {
+		qpathparts["offset"] = strconv.Itoa(offset)
+	}
+	if limit < 0 || limit > maxPageSize {
+		return fmt.Errorf("limit valid range is 0 - %d, got %d", maxPageSize, limit)
+	}
+	if limit > 0 {
+		qpathparts["limit"] = strconv.Itoa(limit)
+	}
+	return nil
+}
+
+// pageFetcher returns the page of items starting at offset, the total number
+// of items, and whether the API reported more pages
+type pageFetcher[T any] func(ctx context.Context, offset, limit int) (items []T, total int, more bool, err error)
+
+// Pager walks a paged list endpoint one item at a time:
+//
+//	pager := client.GetAccountsPager(ctx, nil, nil, nil, &filter, nil, 0)
+//	for pager.Next() {
+//		acct := pager.Item()
+//	}
+//	if err := pager.Err(); err != nil {
+//		...
+//	}
+//
+// Stop calling Next to end early; no further pages are fetched.
+type Pager[T any] struct {
+	ctx      context.Context
+	fetch    pageFetcher[T]
+	pageSize int
+
+	offset int // absolute offset of the next page to fetch
+	page   []T
+	index  int
+	total  int
+	more   bool
+	err    error
+}
+
+// newPager returns a Pager whose first page starts at offset start
+func newPager[T any](ctx context.Context, start, pageSize int, fetch pageFetcher[T]) *Pager[T] {
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	return &Pager[T]{
+		ctx:      ctx,
+		fetch:    fetch,
+		pageSize: min(pageSize, maxPageSize),
+		offset:   max(start, 0),
+		index:    -1,
+		more:     true,
+	}
+}
+
+// Next advances to the next item, fetching the next page when needed; it
+// returns false when there are no more items or an error occurred
+func (p *Pager[T]) Next() bool {
+	if p.err != nil {
+		return false
+	}
+	p.index++
+	for p.index >= len(p.page) {
+		if !p.more {
+			return false
+		}
+		if err := p.ctx.Err(); err != nil {
+			p.err = err
+			return false
+		}
+		items, total, more, err := p.fetch(p.ctx, p.offset, p.pageSize)
+		if err != nil {
+			p.err = err
+			return false
+		}
+		p.page, p.index, p.total = items, 0, total
+		p.offset += len(items)
+		p.more = len(items) > 0 && (more || p.offset < total)
+	}
+	return true
+}
+
+// Item returns the current item
+func (p *Pager[T]) Item() T {
+	return p.page[p.index]
+}
+
+// Total returns the total number of items reported by the last page fetched
+func (p *Pager[T]) Total() int {
+	return p.total
+}
+
+// Err returns the error that stopped Next, if any
+func (p *Pager[T]) Err() error {
+	return p.err
+}
@@ -0,0 +1,61 @@
+package pam
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+// safesServer pages through total safes named safe0, safe1, ... and counts the requests
+func safesServer(t *testing.T, total int, requests *atomic.Int32) *Client {
+	t.Helper()
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		resp := GetSafesResponse{Count: total}
+		for i := offset; i < min(offset+limit, total); i++ {
+			resp.Value = append(resp.Value, GetSafeDetails{SafeName: fmt.Sprintf("safe%d", i)})
+		}
+		json.NewEncoder(w).Encode(resp)
+	})
+	return NewClient(srv.URL, NewConfig("", srv.URL, "", ""))
+}
+
+func TestListSafesPagerOffset(t *testing.T) {
+	tests := []struct {
+		name         string
+		offset       int
+		wantFirst    string
+		wantItems    int
+		wantRequests int32
+	}{
+		{"from start", 0, "safe0", 10, 3},
+		{"from offset", 4, "safe4", 6, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			client := safesServer(t, 10, &requests)
+
+			pager := client.ListSafesPager(context.Background(), ListSafesOptions{Offset: tt.offset, Limit: 4})
+			var names []string
+			for pager.Next() {
+				names = append(names, pager.Item().SafeName)
+			}
+			if err := pager.Err(); err != nil {
+				t.Fatalf("pager.Err() = %v", err)
+			}
+			if len(names) != tt.wantItems || names[0] != tt.wantFirst {
+				t.Errorf("pager items = %v, want %d starting at %s", names, tt.wantItems, tt.wantFirst)
+			}
+			if n := requests.Load(); n != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", n, tt.wantRequests)
+			}
+		})
+	}
+}
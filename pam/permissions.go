@@ -0,0 +1,106 @@
+package pam
+
+import "reflect"
+
+// Permission presets matching the safe member roles in the portal.  Each
+// returns a new value, so presets can be combined with Union and adjusted
+// with With:
+//
+//	perms := ReadOnlyPermissions().With(func(p *Permissions) {
+//		p.ViewAuditLog = true
+//	})
+
+// ConnectOnlyPermissions lets the member list accounts and connect with them, without seeing secrets
+func ConnectOnlyPermissions() Permissions {
+	return Permissions{
+		ListAccounts: true,
+		UseAccounts:  true,
+	}
+}
+
+// ReadOnlyPermissions lets the member list, use and retrieve accounts
+func ReadOnlyPermissions() Permissions {
+	return Permissions{
+		ListAccounts:     true,
+		UseAccounts:      true,
+		RetrieveAccounts: true,
+	}
+}
+
+// AccountsConsumerPermissions is ReadOnlyPermissions plus viewing the audit log and safe members
+func AccountsConsumerPermissions() Permissions {
+	return ReadOnlyPermissions().With(func(p *Permissions) {
+		p.ViewAuditLog = true
+		p.ViewSafeMembers = true
+	})
+}
+
+// ApproverPermissions lets the member authorize account requests and manage safe members
+func ApproverPermissions() Permissions {
+	return Permissions{
+		ListAccounts:                true,
+		ViewSafeMembers:             true,
+		ManageSafeMembers:           true,
+		RequestsAuthorizationLevel1: true,
+	}
+}
+
+// AccountsManagerPermissions lets the member manage accounts and safe members, but not the safe itself
+func AccountsManagerPermissions() Permissions {
+	return Permissions{
+		UseAccounts:                            true,
+		RetrieveAccounts:                       true,
+		ListAccounts:                           true,
+		AddAccounts:                            true,
+		UpdateAccountContent:                   true,
+		UpdateAccountProperties:                true,
+		InitiateCPMAccountManagementOperations: true,
+		SpecifyNextAccountContent:              true,
+		RenameAccounts:                         true,
+		DeleteAccounts:                         true,
+		UnlockAccounts:                         true,
+		ManageSafeMembers:                      true,
+		ViewAuditLog:                           true,
+		ViewSafeMembers:                        true,
+		AccessWithoutConfirmation:              true,
+	}
+}
+
+// FullPermissions grants every permission; authorization is at level 1, as
+// level 1 and level 2 cannot both be set
+func FullPermissions() Permissions {
+	return AccountsManagerPermissions().With(func(p *Permissions) {
+		p.ManageSafe = true
+		p.BackupSafe = true
+		p.CreateFolders = true
+		p.DeleteFolders = true
+		p.MoveAccountsAndFolders = true
+		p.RequestsAuthorizationLevel1 = true
+	})
+}
+
+// With returns a copy of p changed by each override in turn
+func (p Permissions) With(overrides ...func(*Permissions)) Permissions {
+	for _, override := range overrides {
+		override(&p)
+	}
+	return p
+}
+
+// Union returns the permissions granted by p or by any of others; if that
+// would set both authorization levels only level 1 is kept, as FullPermissions does
+func (p Permissions) Union(others ...Permissions) Permissions {
+	union := reflect.ValueOf(&p).Elem()
+	for _, other := range others {
+		o := reflect.ValueOf(other)
+		for i := 0; i < union.NumField(); i++ {
+			if o.Field(i).Bool() {
+				union.Field(i).SetBool(true)
+			}
+		}
+	}
+	if p.RequestsAuthorizationLevel1 && p.RequestsAuthorizationLevel2 {
+		p.RequestsAuthorizationLevel2 = false
+	}
+	return p
+}
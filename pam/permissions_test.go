@@ -0,0 +1,41 @@
+package pam
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestPermissionsUnion(t *testing.T) {
+	level2 := Permissions{ListAccounts: true, RequestsAuthorizationLevel2: true}
+	got := ApproverPermissions().Union(level2, ConnectOnlyPermissions())
+
+	want := ApproverPermissions().With(func(p *Permissions) {
+		p.UseAccounts = true
+	})
+	if got != want {
+		t.Errorf("Union() = %+v, want %+v", got, want)
+	}
+	if got := ConnectOnlyPermissions().Union(level2); !got.RequestsAuthorizationLevel2 {
+		t.Errorf("Union() dropped level 2 without level 1: %+v", got)
+	}
+}
+
+func TestPermissionsMarshalJSON(t *testing.T) {
+	b, err := json.Marshal(ReadOnlyPermissions())
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var keys map[string]bool
+	if err := json.Unmarshal(b, &keys); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if len(keys) != 22 {
+		t.Errorf("json.Marshal() sent %d permissions, want all 22: %s", len(keys), b)
+	}
+	granted := map[string]bool{"listAccounts": true, "useAccounts": true, "retrieveAccounts": true}
+	for key, value := range keys {
+		if value != granted[key] {
+			t.Errorf("%s = %v, want %v", key, value, granted[key])
+		}
+	}
+}
@@ -1,10 +1,7 @@
 package pam
 
 import (
-	"encoding/json"
-	"fmt"
-	"io"
-	"log"
+	"context"
 	"net/http"
 )
 
@@ -68,38 +65,24 @@ type Platform struct {
 }
 
 func (c *Client) GetPlatforms() (GetPlatformsResponse, int, error) {
+	return c.GetPlatformsWithContext(context.Background())
+}
+
+// GetPlatformsWithContext is GetPlatforms with a context that can cancel the request
+func (c *Client) GetPlatformsWithContext(ctx context.Context) (GetPlatformsResponse, int, error) {
 	resp := GetPlatformsResponse{}
 
 	// GET /PasswordVault/API/Platforms/
-	apiurl := fmt.Sprintf("%s/PasswordVault/API/Platforms/", c.Config.PcloudUrl)
+	apiurl := c.apiURL(nil, "Platforms", "")
 
-	req, err := http.NewRequest(http.MethodGet, apiurl, nil)
+	req, err := newJSONRequest(ctx, http.MethodGet, apiurl, "get platforms", nil)
 	if err != nil {
 		return resp, http.StatusConflict, err
 	}
-	// attach the header
-	req.Header = make(http.Header)
-	req.Header.Add("Content-Type", "application/json")
-
-	res, err := c.SendRequest(req)
-	if err != nil {
-		return resp, http.StatusBadGateway, fmt.Errorf("failed to send request. %s", err)
-	}
-
-	// read response body
-	body, error := io.ReadAll(res.Body)
-	if error != nil {
-		log.Println(error)
-	}
-	// close response body
-	defer res.Body.Close()
 
-	err = json.Unmarshal(body, &resp)
+	status, err := c.do(req, "get platforms", &resp)
 	if err != nil {
-		return resp, res.StatusCode, fmt.Errorf("response format failed to parse: %s: %s", err.Error(), string(body))
-	}
-	if res.StatusCode >= 300 {
-		return resp, res.StatusCode, fmt.Errorf("received non-200 status code(%d): %s", res.StatusCode, string(body))
+		return resp, status, err
 	}
 
 	return resp, http.StatusOK, nil
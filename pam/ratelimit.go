@@ -0,0 +1,117 @@
+package pam
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// WithRateLimit - send at most rps requests per second on average, allowing
+// bursts of up to burst requests
+func WithRateLimit(rps float64, burst int) func(*Client) error {
+	return func(c *Client) error {
+		if rps <= 0 {
+			return nil
+		}
+		c.limiter = newRateLimiter(rps, max(burst, 1))
+		return nil
+	}
+}
+
+// WithMaxInFlight - allow at most n requests to be in flight at once; a
+// request is in flight until its response body is closed
+func WithMaxInFlight(n int) func(*Client) error {
+	return func(c *Client) error {
+		if n <= 0 {
+			return nil
+		}
+		c.inflight = make(chan struct{}, n)
+		return nil
+	}
+}
+
+// rateLimiter is a token bucket
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64 // tokens added per second
+	burst  float64 // bucket size
+	tokens float64 // may go negative, which is time owed by waiting callers
+	last   time.Time
+}
+
+func newRateLimiter(rps float64, burst int) *rateLimiter {
+	return &rateLimiter{
+		rate:   rps,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// wait takes a token, blocking until it is available or ctx is done
+func (l *rateLimiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	l.tokens--
+	deficit := -l.tokens
+	l.mu.Unlock()
+
+	if deficit <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(time.Duration(deficit / l.rate * float64(time.Second)))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// give the token back
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// roundTrip sends req once the rate limiter and in-flight cap allow it
+func (c *Client) roundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if c.limiter != nil {
+		if err := c.limiter.wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if c.inflight == nil {
+		return c.httpClient().Do(req)
+	}
+
+	select {
+	case c.inflight <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	release := sync.OnceFunc(func() { <-c.inflight })
+	res, err := c.httpClient().Do(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	res.Body = &releaseOnClose{ReadCloser: res.Body, release: release}
+	return res, nil
+}
+
+// releaseOnClose frees an in-flight slot when the response body is closed
+type releaseOnClose struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releaseOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
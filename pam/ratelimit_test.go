@@ -0,0 +1,84 @@
+package pam
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithMaxInFlight(t *testing.T) {
+	var current, peak atomic.Int32
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		n := current.Add(1)
+		defer current.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		fmt.Fprint(w, `{"id":"1"}`)
+	})
+	client := NewClient(srv.URL, NewConfig("", srv.URL, "", ""), WithMaxInFlight(2))
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, _, err := client.GetAccount("1"); err != nil {
+				t.Errorf("GetAccount() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if p := peak.Load(); p > 2 {
+		t.Errorf("peak in-flight requests = %d, want at most 2", p)
+	}
+}
+
+func TestWithRateLimit(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"1"}`)
+	})
+	client := NewClient(srv.URL, NewConfig("", srv.URL, "", ""), WithRateLimit(20, 1))
+
+	start := time.Now()
+	for range 3 {
+		if _, _, err := client.GetAccount("1"); err != nil {
+			t.Fatalf("GetAccount() error = %v", err)
+		}
+	}
+	// the first request uses the burst, the other two wait 50ms each
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("3 requests at 20/s took %s, want at least 100ms", elapsed)
+	}
+}
+
+func TestRateLimiterWaitCanceled(t *testing.T) {
+	l := newRateLimiter(1, 1)
+	if err := l.wait(context.Background()); err != nil {
+		t.Fatalf("wait() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("wait() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// the canceled wait must give its token back rather than leave a debt
+	l.mu.Lock()
+	tokens := l.tokens
+	l.mu.Unlock()
+	if tokens < -0.1 {
+		t.Errorf("tokens after canceled wait = %.2f, want about 0", tokens)
+	}
+}
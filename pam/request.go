@@ -0,0 +1,129 @@
+package pam
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// apiURL returns the Privilege Cloud API url made of the path segments, each
+// escaped, and the query parameters encoded in sorted key order.  A trailing
+// empty segment gives a trailing slash.
+//
+//	c.apiURL(nil, "Safes", "my safe", "Members", "")  // <PcloudUrl>/PasswordVault/API/Safes/my%20safe/Members/
+func (c *Client) apiURL(params map[string]string, segments ...string) string {
+	var b strings.Builder
+	b.WriteString(strings.TrimSuffix(c.Config.PcloudUrl, "/"))
+	b.WriteString("/PasswordVault/API")
+	for _, segment := range segments {
+		b.WriteByte('/')
+		b.WriteString(url.PathEscape(segment))
+	}
+	if query := encodeQuery(params); query != "" {
+		b.WriteByte('?')
+		b.WriteString(query)
+	}
+	return b.String()
+}
+
+// encodeQuery encodes params sorted by key, with spaces as %20 rather than +,
+// so filter expressions like "safeName eq mysafe" reach the API unchanged
+func encodeQuery(params map[string]string) string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = escapeQuery(key) + "=" + escapeQuery(params[key])
+	}
+	return strings.Join(parts, "&")
+}
+
+func escapeQuery(s string) string {
+	// QueryEscape writes a literal + as %2B, so any + left is a space
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+// unescapeSafeURLID accepts a safe name or the url encoded safeUrlId returned
+// by the API, and returns the plain name for use as a path segment
+func unescapeSafeURLID(safeurlid string) string {
+	name, err := url.PathUnescape(safeurlid)
+	if err != nil {
+		return safeurlid
+	}
+	return name
+}
+
+// newJSONRequest builds a request for apiurl, with body (if not nil) encoded as json
+func newJSONRequest(ctx context.Context, method, apiurl, op string, body any) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		jsonbody, err := json.Marshal(body)
+		if err != nil {
+			return nil, newRequestError(ErrEncodeRequest, op, err)
+		}
+		reader = bytes.NewReader(jsonbody)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, apiurl, reader)
+	if err != nil {
+		return nil, newRequestError(ErrBuildRequest, op, err)
+	}
+	// attach the header
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+// do sends req and parses the json response body into out, if out is not nil.
+// A non-2xx response is returned as an *APIError; out is still filled from
+// the body when it parses, so embedded ErrorResponse fields are populated.
+func (c *Client) do(req *http.Request, op string, out any) (int, error) {
+	body, status, err := c.doRaw(req, op)
+	if err != nil {
+		var apierr *APIError
+		if out != nil && errors.As(err, &apierr) {
+			_ = json.Unmarshal(body, out)
+		}
+		return status, err
+	}
+
+	if out != nil && len(bytes.TrimSpace(body)) > 0 {
+		err = json.Unmarshal(body, out)
+		if err != nil {
+			return status, newRequestError(ErrDecodeResponse, op, fmt.Errorf("%s: %s", err.Error(), string(body)))
+		}
+	}
+	return status, nil
+}
+
+// doRaw sends req and returns the response body; a non-2xx response is
+// returned as an *APIError along with its body
+func (c *Client) doRaw(req *http.Request, op string) ([]byte, int, error) {
+	res, err := c.SendRequest(req)
+	if err != nil {
+		return nil, http.StatusBadGateway, newRequestError(ErrSendRequest, op, err)
+	}
+	// close response body
+	defer res.Body.Close()
+
+	// read response body
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, res.StatusCode, newRequestError(ErrReadResponse, op, err)
+	}
+
+	if res.StatusCode >= 300 {
+		return body, res.StatusCode, newAPIError(res, body)
+	}
+	return body, res.StatusCode, nil
+}
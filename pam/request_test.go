@@ -0,0 +1,57 @@
+package pam
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestAPIURL(t *testing.T) {
+	client := NewClient("https://example.privilegecloud.cyberark.cloud", NewConfig("", "https://example.privilegecloud.cyberark.cloud/", "", ""))
+	base := "https://example.privilegecloud.cyberark.cloud/PasswordVault/API"
+
+	tests := []struct {
+		name     string
+		params   map[string]string
+		segments []string
+		want     string
+	}{
+		{"plain", nil, []string{"Accounts", "12_3"}, base + "/Accounts/12_3"},
+		{"trailing slash", nil, []string{"Safes", "safe1", "Members", ""}, base + "/Safes/safe1/Members/"},
+		{"space in segment", nil, []string{"Safes", "my safe"}, base + "/Safes/my%20safe"},
+		{"slash in segment", nil, []string{"Safes", "a/b", "Members"}, base + "/Safes/a%2Fb/Members"},
+		{"sorted keys", map[string]string{"offset": "10", "limit": "5", "filter": "x", "search": "y"}, []string{"Accounts"},
+			base + "/Accounts?filter=x&limit=5&offset=10&search=y"},
+		{"space in value", map[string]string{"filter": "safeName eq my safe"}, []string{"Accounts"},
+			base + "/Accounts?filter=safeName%20eq%20my%20safe"},
+		{"plus in value", map[string]string{"search": "a+b c"}, []string{"Accounts"}, base + "/Accounts?search=a%2Bb%20c"},
+		{"reserved in key and value", map[string]string{"a&b": "c=d"}, []string{"Accounts"}, base + "/Accounts?a%26b=c%3Dd"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := client.apiURL(tt.params, tt.segments...); got != tt.want {
+				t.Errorf("apiURL() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPIURLOnTheWire(t *testing.T) {
+	var requesturi string
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requesturi = r.RequestURI
+	})
+	client := NewClient(srv.URL, NewConfig("", srv.URL, "", ""))
+
+	apiurl := client.apiURL(map[string]string{"search": "a+b c", "filter": "x"}, "Safes", "a/b c", "Members", "")
+	req, err := newJSONRequest(context.Background(), http.MethodGet, apiurl, "test", nil)
+	if err != nil {
+		t.Fatalf("newJSONRequest() error = %v", err)
+	}
+	if _, err := client.do(req, "test", nil); err != nil {
+		t.Fatalf("do() error = %v", err)
+	}
+	if want := "/PasswordVault/API/Safes/a%2Fb%20c/Members/?filter=x&search=a%2Bb%20c"; requesturi != want {
+		t.Errorf("request URI = %s, want %s", requesturi, want)
+	}
+}
@@ -0,0 +1,107 @@
+package pam
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how SendRequest retries 429, 5xx and network failures
+type RetryPolicy struct {
+	MaxAttempts        int           // total attempts, including the first
+	BaseDelay          time.Duration // delay before the first retry, doubled for each retry after
+	MaxDelay           time.Duration // upper bound for the computed backoff
+	RetryNonIdempotent bool          // also retry POST and PATCH requests
+	OnRetry            func(RetryEvent)
+}
+
+// RetryEvent describes a failed attempt that is about to be retried
+type RetryEvent struct {
+	Attempt    int // the attempt that failed, starting at 1
+	Method     string
+	URL        string
+	StatusCode int   // 0 if no response was received
+	Err        error // transport error, if any
+	Delay      time.Duration
+}
+
+// DefaultRetryPolicy returns a policy of 4 attempts with backoff from 500ms up to 30s
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 4,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    30 * time.Second,
+	}
+}
+
+// WithRetryPolicy - retry failed requests according to policy
+func WithRetryPolicy(policy *RetryPolicy) func(*Client) error {
+	return func(c *Client) error {
+		c.RetryPolicy = policy
+		return nil
+	}
+}
+
+// allows reports whether req may be retried under this policy
+func (p *RetryPolicy) allows(req *http.Request) bool {
+	if p.MaxAttempts <= 1 || !canRewind(req) {
+		return false
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return p.RetryNonIdempotent
+}
+
+// delay returns the backoff with jitter for attempt, or the response's
+// Retry-After if that is longer
+func (p *RetryPolicy) delay(attempt int, res *http.Response) time.Duration {
+	backoff := p.BaseDelay << (attempt - 1)
+	if backoff <= 0 || (p.MaxDelay > 0 && backoff > p.MaxDelay) {
+		backoff = p.MaxDelay
+	}
+	if backoff > 0 {
+		// equal jitter, half fixed and half random
+		backoff = backoff/2 + rand.N(backoff/2+1)
+	}
+
+	if res != nil {
+		if after, ok := parseRetryAfter(res.Header.Get("Retry-After")); ok && after > backoff {
+			return after
+		}
+	}
+	return backoff
+}
+
+func shouldRetry(ctx context.Context, res *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	switch res.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(value); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if when, err := http.ParseTime(value); err == nil {
+		return max(time.Until(when), 0), true
+	}
+	return 0, false
+}
@@ -0,0 +1,123 @@
+package pam
+
+import (
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flakyServer fails the first failures requests with 503 and Retry-After: retryafter
+func flakyServer(t *testing.T, failures int32, retryafter string) (*Client, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= failures {
+			w.Header().Set("Retry-After", retryafter)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"id":"1"}`)
+	})
+	return NewClient(srv.URL, NewConfig("", srv.URL, "", "")), &calls
+}
+
+func testRetryPolicy(events *[]RetryEvent) *RetryPolicy {
+	policy := DefaultRetryPolicy()
+	policy.BaseDelay = time.Millisecond
+	policy.MaxDelay = 10 * time.Millisecond
+	policy.OnRetry = func(e RetryEvent) { *events = append(*events, e) }
+	return policy
+}
+
+func TestRetryPolicyRetriesIdempotent(t *testing.T) {
+	client, calls := flakyServer(t, 2, "0")
+	var events []RetryEvent
+	client.RetryPolicy = testRetryPolicy(&events)
+
+	_, status, err := client.GetAccount("1")
+	if err != nil || status != http.StatusOK {
+		t.Fatalf("GetAccount() = (%d, %v), want (200, nil)", status, err)
+	}
+	if n := calls.Load(); n != 3 {
+		t.Errorf("calls = %d, want 3", n)
+	}
+	if len(events) != 2 || events[0].Attempt != 1 || events[0].StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("retry events = %+v, want 2 starting at attempt 1 with 503", events)
+	}
+}
+
+func TestRetryPolicyGivesUpAfterMaxAttempts(t *testing.T) {
+	client, calls := flakyServer(t, 100, "0")
+	var events []RetryEvent
+	client.RetryPolicy = testRetryPolicy(&events)
+
+	_, status, err := client.GetAccount("1")
+	if err == nil || status != http.StatusServiceUnavailable {
+		t.Fatalf("GetAccount() = (%d, %v), want 503 error", status, err)
+	}
+	if n := calls.Load(); n != int32(client.RetryPolicy.MaxAttempts) {
+		t.Errorf("calls = %d, want %d", n, client.RetryPolicy.MaxAttempts)
+	}
+}
+
+func TestRetryPolicyPost(t *testing.T) {
+	client, calls := flakyServer(t, 1, "0")
+	var events []RetryEvent
+	client.RetryPolicy = testRetryPolicy(&events)
+
+	_, _, err := client.AddAccount(PostAddAccountRequest{SafeName: "safe1"})
+	if err == nil || calls.Load() != 1 {
+		t.Fatalf("AddAccount() error = %v after %d calls, want no retry of POST", err, calls.Load())
+	}
+
+	client.RetryPolicy.RetryNonIdempotent = true
+	calls.Store(0)
+	_, _, err = client.AddAccount(PostAddAccountRequest{SafeName: "safe1"})
+	if err != nil || calls.Load() != 2 {
+		t.Fatalf("AddAccount() error = %v after %d calls, want retried POST", err, calls.Load())
+	}
+}
+
+func TestRetryPolicyHonorsRetryAfter(t *testing.T) {
+	client, _ := flakyServer(t, 1, "1")
+	var events []RetryEvent
+	client.RetryPolicy = testRetryPolicy(&events)
+
+	start := time.Now()
+	_, _, err := client.GetAccount("1")
+	if err != nil {
+		t.Fatalf("GetAccount() error = %v", err)
+	}
+	if len(events) != 1 || events[0].Delay != time.Second {
+		t.Errorf("retry events = %+v, want one with 1s delay", events)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("GetAccount() returned after %s, want at least the 1s Retry-After", elapsed)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	future := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOk bool
+	}{
+		{"", 0, false},
+		{"5", 5 * time.Second, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{"Mon, 01 Jan 2001 00:00:00 GMT", 0, true},
+	}
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value)
+		if got != tt.want || ok != tt.wantOk {
+			t.Errorf("parseRetryAfter(%q) = (%s, %v), want (%s, %v)", tt.value, got, ok, tt.want, tt.wantOk)
+		}
+	}
+	if got, ok := parseRetryAfter(future); !ok || got < 59*time.Minute {
+		t.Errorf("parseRetryAfter(%q) = (%s, %v), want about 1h", future, got, ok)
+	}
+}
@@ -1,13 +1,10 @@
 package pam
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"io"
-	"log"
 	"net/http"
-	"net/url"
-	"strings"
 )
 
 type Creator struct {
@@ -64,80 +61,105 @@ type GetSafeDetails struct {
 }
 
 func (c *Client) AddSafe(safereq PostAddSafeRequest) (PostAddSafeResponse, int, error) {
+	return c.AddSafeWithContext(context.Background(), safereq)
+}
+
+// AddSafeWithContext is AddSafe with a context that can cancel the request
+func (c *Client) AddSafeWithContext(ctx context.Context, safereq PostAddSafeRequest) (PostAddSafeResponse, int, error) {
 	// https://docs.cyberark.com/privilege-cloud-shared-services/latest/en/Content/WebServices/Add%20Safe.htm
 	newsafe := PostAddSafeResponse{}
 
 	// POST /PasswordVault/API/Safes/
-	apiurl := fmt.Sprintf("%s/PasswordVault/API/Safes/", c.Config.PcloudUrl)
-
-	jsonbody, err := json.Marshal(safereq)
-	if err != nil {
-		log.Fatalf("failed to create json body for add safe: %s\n", err.Error())
-	}
+	apiurl := c.apiURL(nil, "Safes", "")
 
-	req, err := http.NewRequest(http.MethodPost, apiurl, strings.NewReader(string(jsonbody)))
+	req, err := newJSONRequest(ctx, http.MethodPost, apiurl, "add safe", safereq)
 	if err != nil {
 		return newsafe, http.StatusConflict, err
 	}
-	// attach the header
-	req.Header = make(http.Header)
-	req.Header.Add("Content-Type", "application/json")
-
-	res, err := c.SendRequest(req)
-	if err != nil {
-		return newsafe, http.StatusBadGateway, fmt.Errorf("failed to send request. %s", err)
-	}
 
-	// read response body
-	body, error := io.ReadAll(res.Body)
-	if error != nil {
-		log.Println(error)
-	}
-	// close response body
-	defer res.Body.Close()
-
-	err = json.Unmarshal(body, &newsafe)
-	if err != nil {
-		return newsafe, res.StatusCode, fmt.Errorf("response format failed to parse: %s: %s", err.Error(), string(body))
-	}
-
-	return newsafe, res.StatusCode, nil
+	status, err := c.do(req, "add safe", &newsafe)
+	return newsafe, status, err
 }
 
 func (c *Client) GetSafeDetails(safename string) (GetSafeDetails, int, error) {
+	return c.GetSafeDetailsWithContext(context.Background(), safename)
+}
+
+// GetSafeDetailsWithContext is GetSafeDetails with a context that can cancel the request
+func (c *Client) GetSafeDetailsWithContext(ctx context.Context, safename string) (GetSafeDetails, int, error) {
 	// https://docs.cyberark.com/privilege-cloud-shared-services/latest/en/content/sdk/safes+web+services+-+get+safes+details.htm
 
 	safedetails := GetSafeDetails{}
 
 	// GET /PasswordVault/API/Safes/{SafeUrlId}/
-	safeurlid := url.QueryEscape(safename)
-	apiurl := fmt.Sprintf("%s/PasswordVault/API/Safes/%s", c.Config.PcloudUrl, safeurlid)
+	apiurl := c.apiURL(nil, "Safes", unescapeSafeURLID(safename))
 
-	req, err := http.NewRequest(http.MethodGet, apiurl, nil)
+	req, err := newJSONRequest(ctx, http.MethodGet, apiurl, "get safe details", nil)
 	if err != nil {
 		return safedetails, http.StatusConflict, err
 	}
-	// attach the header
-	req.Header = make(http.Header)
-	req.Header.Add("Content-Type", "application/json")
 
-	res, err := c.SendRequest(req)
-	if err != nil {
-		return safedetails, http.StatusBadGateway, fmt.Errorf("failed to send request. %s", err)
+	status, err := c.do(req, "get safe details", &safedetails)
+	return safedetails, status, err
+}
+
+// PutUpdateSafeRequest is used to update a safe; numberOfDaysRetention and
+// numberOfVersionsRetention cannot both be set.  A nil Description,
+// OlacEnabled or ManagingCPM is left out, a set one is always sent, so an
+// empty string clears the description or unassigns the managing CPM.
+type PutUpdateSafeRequest struct {
+	SafeName                  string  `json:"safeName"` // Required, set a new name to rename the safe
+	Description               *string `json:"description,omitempty"`
+	Location                  string  `json:"location,omitempty"`
+	NumberOfDaysRetention     int     `json:"numberOfDaysRetention,omitempty"`
+	NumberOfVersionsRetention int     `json:"numberOfVersionsRetention,omitempty"`
+	OlacEnabled               *bool   `json:"olacEnabled,omitempty"`
+	ManagingCPM               *string `json:"managingCPM,omitempty"`
+}
+
+// PutUpdateSafeResponse has the same fields as PostAddSafeResponse
+type PutUpdateSafeResponse PostAddSafeResponse
+
+// ErrSafeDeleteBlocked is returned by DeleteSafe, along with the *APIError,
+// when the safe cannot be deleted yet because of its retention policy
+var ErrSafeDeleteBlocked = errors.New("safe deletion blocked by retention policy")
+
+// UpdateSafe updates the safe's properties
+// REF: <https://docs.cyberark.com/privilege-cloud-shared-services/latest/en/content/webservices/update%20safe.htm>
+func (c *Client) UpdateSafe(ctx context.Context, safeurlid string, safereq PutUpdateSafeRequest) (PutUpdateSafeResponse, int, error) {
+	updatedsafe := PutUpdateSafeResponse{}
+	if safereq.NumberOfDaysRetention > 0 && safereq.NumberOfVersionsRetention > 0 {
+		return updatedsafe, http.StatusBadRequest, errors.New("update safe: set only one of numberOfDaysRetention and numberOfVersionsRetention")
 	}
 
-	// read response body
-	body, error := io.ReadAll(res.Body)
-	if error != nil {
-		log.Println(error)
+	// PUT /PasswordVault/API/Safes/{SafeUrlId}/
+	apiurl := c.apiURL(nil, "Safes", unescapeSafeURLID(safeurlid))
+
+	req, err := newJSONRequest(ctx, http.MethodPut, apiurl, "update safe", safereq)
+	if err != nil {
+		return updatedsafe, http.StatusConflict, err
 	}
-	// close response body
-	defer res.Body.Close()
 
-	err = json.Unmarshal(body, &safedetails)
+	status, err := c.do(req, "update safe", &updatedsafe)
+	return updatedsafe, status, err
+}
+
+// DeleteSafe deletes the safe; if its retention policy does not allow that
+// yet, the error matches ErrSafeDeleteBlocked with errors.Is
+// REF: <https://docs.cyberark.com/privilege-cloud-shared-services/latest/en/content/webservices/delete%20safe.htm>
+func (c *Client) DeleteSafe(ctx context.Context, safeurlid string) (int, error) {
+	// DELETE /PasswordVault/API/Safes/{SafeUrlId}/
+	apiurl := c.apiURL(nil, "Safes", unescapeSafeURLID(safeurlid))
+
+	req, err := newJSONRequest(ctx, http.MethodDelete, apiurl, "delete safe", nil)
 	if err != nil {
-		return safedetails, res.StatusCode, fmt.Errorf("response format failed to parse: %s: %s", err.Error(), string(body))
+		return http.StatusConflict, err
 	}
 
-	return safedetails, res.StatusCode, nil
+	status, err := c.do(req, "delete safe", nil)
+	var apierr *APIError
+	if errors.As(err, &apierr) && isSafeDeleteBlocked(apierr) {
+		return status, fmt.Errorf("%w: %w", ErrSafeDeleteBlocked, apierr)
+	}
+	return status, err
 }
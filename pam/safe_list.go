@@ -0,0 +1,81 @@
+package pam
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+)
+
+type GetSafesResponse struct {
+	Value    []GetSafeDetails `json:"value,omitempty"`
+	Count    int              `json:"count,omitempty"`
+	NextLink string           `json:"nextLink,omitempty"`
+}
+
+// ListSafesOptions selects safes for ListSafes; zero values are not sent
+type ListSafesOptions struct {
+	Search          string
+	Sort            Sort // Ex: Desc("safeName")
+	Offset          int
+	Limit           int  // 1 - 1000, API default is 25
+	IncludeAccounts bool // include the accounts in each safe
+	ExtendedDetails *bool
+}
+
+// query returns the ListSafes query parameters set in o
+func (o ListSafesOptions) query() (map[string]string, error) {
+	qpathparts := map[string]string{}
+	if o.Search != "" {
+		qpathparts["search"] = o.Search
+	}
+	if err := addPageQuery(qpathparts, o.Sort.list(), o.Offset, o.Limit); err != nil {
+		return nil, err
+	}
+	if o.IncludeAccounts {
+		qpathparts["includeAccounts"] = "true"
+	}
+	if o.ExtendedDetails != nil {
+		qpathparts["extendedDetails"] = strconv.FormatBool(*o.ExtendedDetails)
+	}
+	return qpathparts, nil
+}
+
+// ListSafes returns one page of the safes the user is a member of, selected by opts
+// REF: <https://docs.cyberark.com/privilege-cloud-shared-services/latest/en/content/sdk/safes%20web%20services%20-%20list%20safes.htm>
+func (c *Client) ListSafes(ctx context.Context, opts ListSafesOptions) (*GetSafesResponse, int, error) {
+	safesresp := GetSafesResponse{}
+
+	qpathparts, err := opts.query()
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	// GET /PasswordVault/API/Safes?search={search}&sort={sort}&offset={offset}&limit={limit}&includeAccounts={includeAccounts}&extendedDetails={extendedDetails}
+	apiurl := c.apiURL(qpathparts, "Safes")
+
+	req, err := newJSONRequest(ctx, http.MethodGet, apiurl, "list safes", nil)
+	if err != nil {
+		return nil, http.StatusConflict, err
+	}
+
+	status, err := c.do(req, "list safes", &safesresp)
+	if err != nil {
+		return &safesresp, status, err
+	}
+
+	return &safesresp, http.StatusOK, nil
+}
+
+// ListSafesPager walks every safe selected by opts, starting at opts.Offset
+// and fetching opts.Limit safes per request (default 100)
+func (c *Client) ListSafesPager(ctx context.Context, opts ListSafesOptions) *Pager[GetSafeDetails] {
+	return newPager(ctx, opts.Offset, opts.Limit, func(ctx context.Context, offset, limit int) ([]GetSafeDetails, int, bool, error) {
+		page := opts
+		page.Offset, page.Limit = offset, limit
+		resp, _, err := c.ListSafes(ctx, page)
+		if err != nil {
+			return nil, 0, false, err
+		}
+		return resp.Value, resp.Count, resp.NextLink != "", nil
+	})
+}
@@ -1,10 +1,8 @@
 package pam
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
-	"log"
 	"net/http"
 	"strings"
 )
@@ -13,34 +11,36 @@ type PostAddMemberRequest struct {
 	MemberName               string      `json:"memberName,omitempty"`
 	SearchIn                 string      `json:"searchIn,omitempty"`
 	MembershipExpirationDate int         `json:"membershipExpirationDate,omitempty"`
-	Permissions              Permissions `json:"permissions,omitempty"`
+	Permissions              Permissions `json:"permissions"`
 	MemberType               string      `json:"MemberType,omitempty"`
 	IsReadOnly               bool        `json:"isReadOnly,omitempty"`
 }
 
+// Permissions are a safe member's permissions; every field is always sent so
+// that false explicitly revokes.  Start from a preset such as ReadOnlyPermissions.
 type Permissions struct {
-	UseAccounts                            bool `json:"useAccounts,omitempty"`
-	RetrieveAccounts                       bool `json:"retrieveAccounts,omitempty"`
-	ListAccounts                           bool `json:"listAccounts,omitempty"`
-	AddAccounts                            bool `json:"addAccounts,omitempty"`
-	UpdateAccountContent                   bool `json:"updateAccountContent,omitempty"`
-	UpdateAccountProperties                bool `json:"updateAccountProperties,omitempty"`
-	InitiateCPMAccountManagementOperations bool `json:"initiateCPMAccountManagementOperations,omitempty"`
-	SpecifyNextAccountContent              bool `json:"specifyNextAccountContent,omitempty"`
-	RenameAccounts                         bool `json:"renameAccounts,omitempty"`
-	DeleteAccounts                         bool `json:"deleteAccounts,omitempty"`
-	UnlockAccounts                         bool `json:"unlockAccounts,omitempty"`
-	ManageSafe                             bool `json:"manageSafe,omitempty"`
-	ManageSafeMembers                      bool `json:"manageSafeMembers,omitempty"`
-	BackupSafe                             bool `json:"backupSafe,omitempty"`
-	ViewAuditLog                           bool `json:"viewAuditLog,omitempty"`
-	ViewSafeMembers                        bool `json:"viewSafeMembers,omitempty"`
-	AccessWithoutConfirmation              bool `json:"accessWithoutConfirmation,omitempty"`
-	CreateFolders                          bool `json:"createFolders,omitempty"`
-	DeleteFolders                          bool `json:"deleteFolders,omitempty"`
-	MoveAccountsAndFolders                 bool `json:"moveAccountsAndFolders,omitempty"`
-	RequestsAuthorizationLevel1            bool `json:"requestsAuthorizationLevel1,omitempty"`
-	RequestsAuthorizationLevel2            bool `json:"requestsAuthorizationLevel2,omitempty"`
+	UseAccounts                            bool `json:"useAccounts"`
+	RetrieveAccounts                       bool `json:"retrieveAccounts"`
+	ListAccounts                           bool `json:"listAccounts"`
+	AddAccounts                            bool `json:"addAccounts"`
+	UpdateAccountContent                   bool `json:"updateAccountContent"`
+	UpdateAccountProperties                bool `json:"updateAccountProperties"`
+	InitiateCPMAccountManagementOperations bool `json:"initiateCPMAccountManagementOperations"`
+	SpecifyNextAccountContent              bool `json:"specifyNextAccountContent"`
+	RenameAccounts                         bool `json:"renameAccounts"`
+	DeleteAccounts                         bool `json:"deleteAccounts"`
+	UnlockAccounts                         bool `json:"unlockAccounts"`
+	ManageSafe                             bool `json:"manageSafe"`
+	ManageSafeMembers                      bool `json:"manageSafeMembers"`
+	BackupSafe                             bool `json:"backupSafe"`
+	ViewAuditLog                           bool `json:"viewAuditLog"`
+	ViewSafeMembers                        bool `json:"viewSafeMembers"`
+	AccessWithoutConfirmation              bool `json:"accessWithoutConfirmation"`
+	CreateFolders                          bool `json:"createFolders"`
+	DeleteFolders                          bool `json:"deleteFolders"`
+	MoveAccountsAndFolders                 bool `json:"moveAccountsAndFolders"`
+	RequestsAuthorizationLevel1            bool `json:"requestsAuthorizationLevel1"`
+	RequestsAuthorizationLevel2            bool `json:"requestsAuthorizationLevel2"`
 }
 
 type PostAddMemberResponse struct {
@@ -57,47 +57,184 @@ type PostAddMemberResponse struct {
 }
 
 func (c *Client) AddSafeMember(member PostAddMemberRequest, safeurlid string) (PostAddMemberResponse, int, error) {
+	return c.AddSafeMemberWithContext(context.Background(), member, safeurlid)
+}
+
+// AddSafeMemberWithContext is AddSafeMember with a context that can cancel the request
+func (c *Client) AddSafeMemberWithContext(ctx context.Context, member PostAddMemberRequest, safeurlid string) (PostAddMemberResponse, int, error) {
 	// https://docs.cyberark.com/privilege-cloud-shared-services/latest/en/content/webservices/add+safe+member.htm
 
 	addMemberResponse := PostAddMemberResponse{}
 
 	// POST /PasswordVault/API/Safes/{safeUrlId}/Members/
-	apiurl := fmt.Sprintf("%s/PasswordVault/API/Safes/%s/Members/", c.Config.PcloudUrl, safeurlid)
+	apiurl := c.apiURL(nil, "Safes", unescapeSafeURLID(safeurlid), "Members", "")
 
-	jsonbody, err := json.Marshal(member)
+	req, err := newJSONRequest(ctx, http.MethodPost, apiurl, "add safe member", member)
 	if err != nil {
-		log.Fatalf("failed to create json body for safe member: %s\n", err.Error())
+		return addMemberResponse, http.StatusConflict, err
 	}
-	req, err := http.NewRequest(http.MethodPost, apiurl, strings.NewReader(string(jsonbody)))
+
+	status, err := c.do(req, "add safe member", &addMemberResponse)
 	if err != nil {
-		return addMemberResponse, http.StatusConflict, err
+		return addMemberResponse, status, err
 	}
 
-	// attach the header
-	req.Header = make(http.Header)
-	req.Header.Add("Content-Type", "application/json")
+	return addMemberResponse, http.StatusOK, nil
+}
+
+// MemberType is the kind of safe member
+type MemberType string
+
+const (
+	MemberTypeUser  MemberType = "User"
+	MemberTypeGroup MemberType = "Group"
+	MemberTypeRole  MemberType = "Role"
+)
+
+type GetSafeMembersResponse struct {
+	Value    []PostAddMemberResponse `json:"value,omitempty"`
+	Count    int                     `json:"count,omitempty"`
+	NextLink string                  `json:"nextLink,omitempty"`
+}
+
+// ListSafeMembersOptions selects members for ListSafeMembers; zero values are not sent
+type ListSafeMembersOptions struct {
+	MemberType             MemberType // User or Group
+	IncludePredefinedUsers bool
+	Search                 string
+	Sort                   Sort // Ex: Asc("memberName")
+	Offset                 int
+	Limit                  int // 1 - 1000, API default is 25
+}
+
+// query builds the filter, search and paging parameters for ListSafeMembers
+func (o ListSafeMembersOptions) query() (map[string]string, error) {
+	qpathparts := map[string]string{}
 
-	res, err := c.SendRequest(req)
+	filters := []string{}
+	if o.MemberType != "" {
+		if o.MemberType != MemberTypeUser && o.MemberType != MemberTypeGroup {
+			return nil, fmt.Errorf("invalid memberType filter: %s, must be '%s' or '%s'", o.MemberType, MemberTypeUser, MemberTypeGroup)
+		}
+		filters = append(filters, fmt.Sprintf("memberType eq %s", o.MemberType))
+	}
+	if o.IncludePredefinedUsers {
+		filters = append(filters, "includePredefinedUsers eq true")
+	}
+	if len(filters) > 0 {
+		qpathparts["filter"] = strings.Join(filters, " AND ")
+	}
+
+	if o.Search != "" {
+		qpathparts["search"] = o.Search
+	}
+	if err := addPageQuery(qpathparts, o.Sort.list(), o.Offset, o.Limit); err != nil {
+		return nil, err
+	}
+	return qpathparts, nil
+}
+
+// ListSafeMembers returns one page of the safe's members, selected by opts
+// REF: <https://docs.cyberark.com/privilege-cloud-shared-services/latest/en/content/webservices/safe%20members%20ws%20-%20list%20safe%20members.htm>
+func (c *Client) ListSafeMembers(ctx context.Context, safeurlid string, opts ListSafeMembersOptions) (*GetSafeMembersResponse, int, error) {
+	membersresp := GetSafeMembersResponse{}
+
+	qpathparts, err := opts.query()
 	if err != nil {
-		return addMemberResponse, http.StatusBadGateway, fmt.Errorf("failed to send request. %s", err)
+		return nil, http.StatusBadRequest, err
 	}
 
-	// read response body
-	body, error := io.ReadAll(res.Body)
-	if error != nil {
-		log.Println(error)
+	// GET /PasswordVault/API/Safes/{safeUrlId}/Members?filter={filter}&search={search}&sort={sort}&offset={offset}&limit={limit}
+	apiurl := c.apiURL(qpathparts, "Safes", unescapeSafeURLID(safeurlid), "Members")
+
+	req, err := newJSONRequest(ctx, http.MethodGet, apiurl, "list safe members", nil)
+	if err != nil {
+		return nil, http.StatusConflict, err
 	}
-	// close response body
-	defer res.Body.Close()
 
-	err = json.Unmarshal(body, &addMemberResponse)
+	status, err := c.do(req, "list safe members", &membersresp)
 	if err != nil {
-		return addMemberResponse, res.StatusCode, fmt.Errorf("response format failed to parse: %s: %s", err.Error(), string(body))
+		return &membersresp, status, err
 	}
-	if res.StatusCode >= 300 {
-		return addMemberResponse, res.StatusCode, fmt.Errorf("received non-200 status code(%d): %s", res.StatusCode, string(body))
+
+	return &membersresp, http.StatusOK, nil
+}
+
+// ListSafeMembersPager walks every member of the safe selected by opts,
+// starting at opts.Offset and fetching opts.Limit members per request (default 100)
+func (c *Client) ListSafeMembersPager(ctx context.Context, safeurlid string, opts ListSafeMembersOptions) *Pager[PostAddMemberResponse] {
+	return newPager(ctx, opts.Offset, opts.Limit, func(ctx context.Context, offset, limit int) ([]PostAddMemberResponse, int, bool, error) {
+		page := opts
+		page.Offset, page.Limit = offset, limit
+		resp, _, err := c.ListSafeMembers(ctx, safeurlid, page)
+		if err != nil {
+			return nil, 0, false, err
+		}
+		return resp.Value, resp.Count, resp.NextLink != "", nil
+	})
+}
+
+// GetSafeMember returns one member of the safe
+func (c *Client) GetSafeMember(ctx context.Context, safeurlid string, membername string) (PostAddMemberResponse, int, error) {
+	member := PostAddMemberResponse{}
+
+	// GET /PasswordVault/API/Safes/{safeUrlId}/Members/{memberName}/
+	apiurl := c.apiURL(nil, "Safes", unescapeSafeURLID(safeurlid), "Members", membername)
+
+	req, err := newJSONRequest(ctx, http.MethodGet, apiurl, "get safe member", nil)
+	if err != nil {
+		return member, http.StatusConflict, err
 	}
 
-	return addMemberResponse, http.StatusOK, nil
+	status, err := c.do(req, "get safe member", &member)
+	if err != nil {
+		return member, status, err
+	}
+
+	return member, http.StatusOK, nil
+}
+
+// PutUpdateMemberRequest is used to change a safe member's permissions and
+// expiration; a nil MembershipExpirationDate is sent as null, which clears it
+type PutUpdateMemberRequest struct {
+	MembershipExpirationDate *int        `json:"membershipExpirationDate"` // epoch seconds
+	Permissions              Permissions `json:"permissions"`
+}
+
+// UpdateSafeMember replaces the member's permissions and expiration date
+// REF: <https://docs.cyberark.com/privilege-cloud-shared-services/latest/en/content/webservices/update%20safe%20member.htm>
+func (c *Client) UpdateSafeMember(ctx context.Context, safeurlid string, membername string, memberreq PutUpdateMemberRequest) (PostAddMemberResponse, int, error) {
+	member := PostAddMemberResponse{}
+
+	// PUT /PasswordVault/API/Safes/{safeUrlId}/Members/{memberName}/
+	apiurl := c.apiURL(nil, "Safes", unescapeSafeURLID(safeurlid), "Members", membername)
 
+	req, err := newJSONRequest(ctx, http.MethodPut, apiurl, "update safe member", memberreq)
+	if err != nil {
+		return member, http.StatusConflict, err
+	}
+
+	status, err := c.do(req, "update safe member", &member)
+	if err != nil {
+		return member, status, err
+	}
+
+	return member, http.StatusOK, nil
+}
+
+// RemoveSafeMember removes the member from the safe
+func (c *Client) RemoveSafeMember(ctx context.Context, safeurlid string, membername string) (int, error) {
+	// DELETE /PasswordVault/API/Safes/{safeUrlId}/Members/{memberName}/
+	apiurl := c.apiURL(nil, "Safes", unescapeSafeURLID(safeurlid), "Members", membername)
+
+	req, err := newJSONRequest(ctx, http.MethodDelete, apiurl, "remove safe member", nil)
+	if err != nil {
+		return http.StatusConflict, err
+	}
+
+	status, err := c.do(req, "remove safe member", nil)
+	if err != nil {
+		return status, err
+	}
+	return http.StatusOK, nil
 }
@@ -0,0 +1,227 @@
+package pam
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// MembershipActionType is what a MembershipAction does to a safe member
+type MembershipActionType string
+
+const (
+	MembershipAdd    MembershipActionType = "add"
+	MembershipUpdate MembershipActionType = "update"
+	MembershipRemove MembershipActionType = "remove"
+)
+
+// MembershipAction is one change needed to converge a safe's members
+type MembershipAction struct {
+	Type       MembershipActionType
+	MemberName string
+	Desired    PostAddMemberRequest  // for add and update
+	Current    PostAddMemberResponse // for update and remove
+}
+
+func (a MembershipAction) String() string {
+	switch a.Type {
+	case MembershipAdd:
+		name := a.MemberName
+		if a.Desired.MemberType != "" {
+			name = a.Desired.MemberType + " " + name
+		}
+		return fmt.Sprintf("add %s: %s", name, strings.Join(permissionChanges(Permissions{}, a.Desired.Permissions), " "))
+	case MembershipUpdate:
+		changes := permissionChanges(a.Current.Permissions, a.Desired.Permissions)
+		switch exp := a.Desired.MembershipExpirationDate; {
+		case exp == a.Current.MembershipExpirationDate:
+		case exp == 0:
+			changes = append(changes, "membershipExpirationDate=none")
+		default:
+			changes = append(changes, fmt.Sprintf("membershipExpirationDate=%d", exp))
+		}
+		return fmt.Sprintf("update %s: %s", a.MemberName, strings.Join(changes, " "))
+	default:
+		return fmt.Sprintf("%s %s", a.Type, a.MemberName)
+	}
+}
+
+// MembershipPlan is the set of actions that converge a safe's members to the desired set
+type MembershipPlan struct {
+	SafeURLID string
+	Actions   []MembershipAction
+}
+
+// String lists the plan one action per line, for dry runs
+func (p MembershipPlan) String() string {
+	if len(p.Actions) == 0 {
+		return fmt.Sprintf("safe %s: no changes\n", p.SafeURLID)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "safe %s: %d changes\n", p.SafeURLID, len(p.Actions))
+	for _, action := range p.Actions {
+		fmt.Fprintf(&b, "  %s\n", action)
+	}
+	return b.String()
+}
+
+// ReconcileOptions control which members PlanSafeMembership may remove
+type ReconcileOptions struct {
+	// AllowRemove plans the removal of members not in desired; without it
+	// the plan only adds and updates
+	AllowRemove bool
+	// ProtectedMembers are never removed, in addition to predefined users
+	// and the client's own user.  Names match ignoring case.
+	ProtectedMembers []string
+}
+
+// MembershipResult is the outcome of applying one MembershipAction
+type MembershipResult struct {
+	Action     MembershipAction
+	StatusCode int
+	Err        error
+}
+
+// PlanSafeMembership compares the safe's current members with desired and
+// returns the actions to converge them: add missing members, update members
+// whose permissions or expiration differ, and, if opts.AllowRemove, remove
+// members not in desired.  Predefined users, the client's own user and
+// opts.ProtectedMembers are never removed.  A member whose type differs from
+// desired is replaced, which also needs opts.AllowRemove.  Member names match
+// ignoring case; a desired expiration of 0 means the membership never expires.
+func (c *Client) PlanSafeMembership(ctx context.Context, safeurlid string, desired []PostAddMemberRequest, opts ReconcileOptions) (MembershipPlan, int, error) {
+	plan := MembershipPlan{SafeURLID: safeurlid}
+
+	protected := map[string]bool{}
+	for _, name := range opts.ProtectedMembers {
+		protected[strings.ToLower(name)] = true
+	}
+	if c.Config != nil && c.Config.User != "" {
+		protected[strings.ToLower(c.Config.User)] = true
+	}
+	removable := func(member PostAddMemberResponse) bool {
+		return opts.AllowRemove && !member.IsPredefinedUser && !protected[strings.ToLower(member.MemberName)]
+	}
+
+	wanted := map[string]PostAddMemberRequest{}
+	for _, member := range desired {
+		key := strings.ToLower(member.MemberName)
+		if key == "" {
+			return plan, http.StatusBadRequest, fmt.Errorf("desired member has no memberName")
+		}
+		if _, dup := wanted[key]; dup {
+			return plan, http.StatusBadRequest, fmt.Errorf("desired member %s is listed more than once", member.MemberName)
+		}
+		wanted[key] = member
+	}
+
+	current := map[string]PostAddMemberResponse{}
+	currentorder := []PostAddMemberResponse{}
+	// predefined users are listed so a desired one is compared, not added again
+	pager := c.ListSafeMembersPager(ctx, safeurlid, ListSafeMembersOptions{IncludePredefinedUsers: true})
+	for pager.Next() {
+		member := pager.Item()
+		current[strings.ToLower(member.MemberName)] = member
+		currentorder = append(currentorder, member)
+	}
+	if err := pager.Err(); err != nil {
+		var apierr *APIError
+		if errors.As(err, &apierr) {
+			return plan, apierr.StatusCode, err
+		}
+		return plan, http.StatusBadGateway, err
+	}
+
+	// adds and updates, in the order desired lists them
+	for _, member := range desired {
+		have, ok := current[strings.ToLower(member.MemberName)]
+		switch {
+		case !ok:
+			plan.Actions = append(plan.Actions, MembershipAction{Type: MembershipAdd, MemberName: member.MemberName, Desired: member})
+		case member.MemberType != "" && have.MemberType != "" && !strings.EqualFold(member.MemberType, have.MemberType):
+			// the type cannot be updated, the member has to be removed and added again
+			if !removable(have) {
+				return plan, http.StatusBadRequest, fmt.Errorf("member %s is a %s, not a %s, and cannot be removed to replace it", have.MemberName, have.MemberType, member.MemberType)
+			}
+			plan.Actions = append(plan.Actions,
+				MembershipAction{Type: MembershipRemove, MemberName: have.MemberName, Current: have},
+				MembershipAction{Type: MembershipAdd, MemberName: member.MemberName, Desired: member})
+		case have.Permissions != member.Permissions || have.MembershipExpirationDate != member.MembershipExpirationDate:
+			plan.Actions = append(plan.Actions, MembershipAction{Type: MembershipUpdate, MemberName: have.MemberName, Desired: member, Current: have})
+		}
+	}
+
+	// removes, in the order the safe lists them
+	for _, have := range currentorder {
+		if _, ok := wanted[strings.ToLower(have.MemberName)]; !ok && removable(have) {
+			plan.Actions = append(plan.Actions, MembershipAction{Type: MembershipRemove, MemberName: have.MemberName, Current: have})
+		}
+	}
+
+	return plan, http.StatusOK, nil
+}
+
+// ApplySafeMembershipPlan runs the plan's actions in order and reports the
+// outcome of each; a failed action does not stop the ones after it
+func (c *Client) ApplySafeMembershipPlan(ctx context.Context, plan MembershipPlan) []MembershipResult {
+	results := make([]MembershipResult, 0, len(plan.Actions))
+	for _, action := range plan.Actions {
+		if err := ctx.Err(); err != nil {
+			results = append(results, MembershipResult{Action: action, Err: err})
+			continue
+		}
+
+		var status int
+		var err error
+		switch action.Type {
+		case MembershipAdd:
+			_, status, err = c.AddSafeMemberWithContext(ctx, action.Desired, plan.SafeURLID)
+		case MembershipUpdate:
+			// an expiration of 0 is sent as null, which clears it
+			update := PutUpdateMemberRequest{Permissions: action.Desired.Permissions}
+			if exp := action.Desired.MembershipExpirationDate; exp != 0 {
+				update.MembershipExpirationDate = &exp
+			}
+			_, status, err = c.UpdateSafeMember(ctx, plan.SafeURLID, action.MemberName, update)
+		case MembershipRemove:
+			status, err = c.RemoveSafeMember(ctx, plan.SafeURLID, action.MemberName)
+		default:
+			status, err = http.StatusBadRequest, fmt.Errorf("unknown membership action: %s", action.Type)
+		}
+		results = append(results, MembershipResult{Action: action, StatusCode: status, Err: err})
+	}
+	return results
+}
+
+// ReconcileSafeMembers plans the changes that converge the safe's members to
+// desired, see PlanSafeMembership, and unless dryRun applies them; print the
+// plan to show a dry run
+func (c *Client) ReconcileSafeMembers(ctx context.Context, safeurlid string, desired []PostAddMemberRequest, opts ReconcileOptions, dryRun bool) (MembershipPlan, []MembershipResult, error) {
+	plan, _, err := c.PlanSafeMembership(ctx, safeurlid, desired, opts)
+	if err != nil || dryRun {
+		return plan, nil, err
+	}
+	return plan, c.ApplySafeMembershipPlan(ctx, plan), nil
+}
+
+// permissionChanges lists the permissions that differ, as +name for granted and -name for revoked
+func permissionChanges(from, to Permissions) []string {
+	changes := []string{}
+	fromv, tov := reflect.ValueOf(from), reflect.ValueOf(to)
+	fields := reflect.TypeOf(to)
+	for i := 0; i < fields.NumField(); i++ {
+		if fromv.Field(i).Bool() == tov.Field(i).Bool() {
+			continue
+		}
+		name, _, _ := strings.Cut(fields.Field(i).Tag.Get("json"), ",")
+		if tov.Field(i).Bool() {
+			changes = append(changes, "+"+name)
+		} else {
+			changes = append(changes, "-"+name)
+		}
+	}
+	return changes
+}
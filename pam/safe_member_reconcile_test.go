@@ -0,0 +1,59 @@
+package pam
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sync/atomic"
+	"testing"
+)
+
+func TestPlanSafeMembership(t *testing.T) {
+	members := []PostAddMemberResponse{
+		{MemberName: "Administrator", MemberType: "User", IsPredefinedUser: true},
+		{MemberName: "USER", MemberType: "User", Permissions: ReadOnlyPermissions()}, // the client's own user
+		{MemberName: "keep", MemberType: "User"},
+		{MemberName: "old", MemberType: "User"},
+		{MemberName: "expiring", MemberType: "User", Permissions: ReadOnlyPermissions(), MembershipExpirationDate: 1767225600},
+		{MemberName: "ops", MemberType: "Group", Permissions: ReadOnlyPermissions()},
+	}
+	var tokens atomic.Int32
+	client := tokenServer(t, &tokens, func(w http.ResponseWriter, r *http.Request) {
+		listed := members
+		if r.URL.Query().Get("filter") != "includePredefinedUsers eq true" {
+			listed = slices.DeleteFunc(slices.Clone(members), func(m PostAddMemberResponse) bool { return m.IsPredefinedUser })
+		}
+		json.NewEncoder(w).Encode(GetSafeMembersResponse{Value: listed, Count: len(listed)})
+	})
+
+	desired := []PostAddMemberRequest{
+		{MemberName: "expiring", Permissions: ReadOnlyPermissions()}, // clear the expiration
+		{MemberName: "ops", MemberType: "user", Permissions: ReadOnlyPermissions()},
+		{MemberName: "new", Permissions: ReadOnlyPermissions()},
+		{MemberName: "administrator", Permissions: FullPermissions()}, // predefined, compared not added
+	}
+
+	if _, _, err := client.PlanSafeMembership(context.Background(), "safe1", desired, ReconcileOptions{}); err == nil {
+		t.Errorf("PlanSafeMembership() replaced a member of a different type without AllowRemove")
+	}
+
+	plan, status, err := client.PlanSafeMembership(context.Background(), "safe1", desired, ReconcileOptions{AllowRemove: true, ProtectedMembers: []string{"Keep"}})
+	if err != nil || status != http.StatusOK {
+		t.Fatalf("PlanSafeMembership() = (%d, %v)", status, err)
+	}
+	got := []string{}
+	for _, action := range plan.Actions {
+		got = append(got, string(action.Type)+" "+action.MemberName)
+	}
+	want := []string{"update expiring", "remove ops", "add ops", "add new", "update Administrator", "remove old"}
+	if !slices.Equal(got, want) {
+		t.Errorf("plan actions = %v, want %v", got, want)
+	}
+
+	desired = desired[:1]
+	plan, _, err = client.PlanSafeMembership(context.Background(), "safe1", desired, ReconcileOptions{})
+	if err != nil || len(plan.Actions) != 1 || plan.Actions[0].Type != MembershipUpdate {
+		t.Errorf("PlanSafeMembership() without AllowRemove = %v, %v, want only the update", plan.Actions, err)
+	}
+}
@@ -0,0 +1,72 @@
+package pam
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestAddSafeMemberErrors(t *testing.T) {
+	badjson := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "not json")
+	})
+	truncated := newTestServer(t, truncatedBody)
+
+	tests := []struct {
+		name     string
+		pcloud   string
+		wantKind error
+	}{
+		{"build", "http://bad host", ErrBuildRequest},
+		{"send", closedServerURL(t), ErrSendRequest},
+		{"read", truncated.URL, ErrReadResponse},
+		{"decode", badjson.URL, ErrDecodeResponse},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient(tt.pcloud, NewConfig("", tt.pcloud, "", ""))
+			_, _, err := client.AddSafeMember(PostAddMemberRequest{MemberName: "user1"}, "safe1")
+			if !errors.Is(err, tt.wantKind) {
+				t.Fatalf("AddSafeMember() error = %v, want %v", err, tt.wantKind)
+			}
+		})
+	}
+}
+
+func TestUpdateSafeMemberExpiration(t *testing.T) {
+	var body map[string]any
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		body = nil
+		json.NewDecoder(r.Body).Decode(&body)
+		fmt.Fprint(w, `{"memberName":"user1"}`)
+	})
+	client := NewClient(srv.URL, NewConfig("", srv.URL, "", ""))
+
+	exp := 1767225600
+	tests := []struct {
+		name string
+		exp  *int
+		want any
+	}{
+		{"clear", nil, nil},
+		{"set", &exp, float64(exp)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := client.UpdateSafeMember(context.Background(), "safe1", "user1", PutUpdateMemberRequest{MembershipExpirationDate: tt.exp})
+			if err != nil {
+				t.Fatalf("UpdateSafeMember() error = %v", err)
+			}
+			got, ok := body["membershipExpirationDate"]
+			if !ok || got != tt.want {
+				t.Errorf("membershipExpirationDate = %v (sent %v), want %v", got, ok, tt.want)
+			}
+			if _, ok := body["permissions"].(map[string]any); !ok {
+				t.Errorf("permissions not sent: %v", body)
+			}
+		})
+	}
+}
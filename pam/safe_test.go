@@ -0,0 +1,126 @@
+package pam
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestAddSafeErrors(t *testing.T) {
+	badjson := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "not json")
+	})
+	truncated := newTestServer(t, truncatedBody)
+
+	tests := []struct {
+		name     string
+		pcloud   string
+		wantKind error
+	}{
+		{"build", "http://bad host", ErrBuildRequest},
+		{"send", closedServerURL(t), ErrSendRequest},
+		{"read", truncated.URL, ErrReadResponse},
+		{"decode", badjson.URL, ErrDecodeResponse},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient(tt.pcloud, NewConfig("", tt.pcloud, "", ""))
+			_, _, err := client.AddSafe(PostAddSafeRequest{SafeName: "safe1"})
+			if !errors.Is(err, tt.wantKind) {
+				t.Fatalf("AddSafe() error = %v, want %v", err, tt.wantKind)
+			}
+		})
+	}
+}
+
+func TestNewJSONRequestEncodeError(t *testing.T) {
+	// AddSafe and AddSafeMember encode their bodies with newJSONRequest
+	_, err := newJSONRequest(context.Background(), http.MethodPost, "http://localhost/", "add safe", make(chan int))
+	if !errors.Is(err, ErrEncodeRequest) {
+		t.Fatalf("newJSONRequest() error = %v, want %v", err, ErrEncodeRequest)
+	}
+}
+
+func TestAddSafeAlreadyExists(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		fmt.Fprint(w, `{"ErrorCode":"SFWS0002","ErrorMessage":"Safe safe1 already exists."}`)
+	})
+
+	client := NewClient(srv.URL, NewConfig("", srv.URL, "", ""))
+	resp, status, err := client.AddSafe(PostAddSafeRequest{SafeName: "safe1"})
+	if !IsAlreadyExists(err) {
+		t.Fatalf("AddSafe() error = %v, want already exists", err)
+	}
+	if status != http.StatusConflict || resp.ErrorCode != "SFWS0002" {
+		t.Errorf("AddSafe() = (%d, %q), want (409, SFWS0002)", status, resp.ErrorCode)
+	}
+}
+
+func TestDeleteSafeBlockedByRetention(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		wantBlocked bool
+	}{
+		{"retention", `{"ErrorCode":"ITATS530E","ErrorMessage":"Safe safe1 cannot be deleted because it contains files that are not yet ready for deletion."}`, true},
+		{"other", `{"ErrorCode":"SFWS0013","ErrorMessage":"Retention settings are invalid."}`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, tt.body)
+			})
+
+			client := NewClient(srv.URL, NewConfig("", srv.URL, "", ""))
+			status, err := client.DeleteSafe(context.Background(), "safe1")
+			if status != http.StatusBadRequest || err == nil {
+				t.Fatalf("DeleteSafe() = (%d, %v), want 400 error", status, err)
+			}
+			if got := errors.Is(err, ErrSafeDeleteBlocked); got != tt.wantBlocked {
+				t.Errorf("errors.Is(err, ErrSafeDeleteBlocked) = %v, want %v", got, tt.wantBlocked)
+			}
+			var apierr *APIError
+			if !errors.As(err, &apierr) {
+				t.Errorf("DeleteSafe() error = %v, want *APIError", err)
+			}
+		})
+	}
+}
+
+func TestUpdateSafeBody(t *testing.T) {
+	var body map[string]any
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		body = nil
+		json.NewDecoder(r.Body).Decode(&body)
+		fmt.Fprint(w, `{"safeName":"safe1"}`)
+	})
+	client := NewClient(srv.URL, NewConfig("", srv.URL, "", ""))
+
+	empty, cpm, olac := "", "PasswordManager", false
+	tests := []struct {
+		name string
+		req  PutUpdateSafeRequest
+		want map[string]any
+	}{
+		{"unset", PutUpdateSafeRequest{SafeName: "safe1"}, map[string]any{"safeName": "safe1"}},
+		{"clear", PutUpdateSafeRequest{SafeName: "safe1", Description: &empty, ManagingCPM: &empty, OlacEnabled: &olac},
+			map[string]any{"safeName": "safe1", "description": "", "managingCPM": "", "olacEnabled": false}},
+		{"set", PutUpdateSafeRequest{SafeName: "safe1", ManagingCPM: &cpm}, map[string]any{"safeName": "safe1", "managingCPM": cpm}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := client.UpdateSafe(context.Background(), "safe1", tt.req); err != nil {
+				t.Fatalf("UpdateSafe() error = %v", err)
+			}
+			if !reflect.DeepEqual(body, tt.want) {
+				t.Errorf("UpdateSafe() sent %v, want %v", body, tt.want)
+			}
+		})
+	}
+}
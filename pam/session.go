@@ -1,10 +1,10 @@
 package pam
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -56,6 +56,11 @@ func WithTokenInfo(tok string, toktype string, exp time.Time) func(*Session) err
 }
 
 func (c *Client) GetSession() (*Session, int, error) {
+	return c.GetSessionWithContext(context.Background())
+}
+
+// GetSessionWithContext is GetSession with a context that can cancel the token request
+func (c *Client) GetSessionWithContext(ctx context.Context) (*Session, int, error) {
 	identurl := fmt.Sprintf("%s/oauth2/platformtoken", c.Config.IdTenantUrl) // Use PCloud OAuth
 
 	data := url.Values{}
@@ -64,30 +69,43 @@ func (c *Client) GetSession() (*Session, int, error) {
 	data.Set("client_secret", c.Config.Pass)
 	encodedData := data.Encode()
 
-	req, err := http.NewRequest(http.MethodPost, identurl, strings.NewReader(encodedData))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, identurl, strings.NewReader(encodedData))
 	if err != nil {
-		log.Fatalf("error in request to get session token: %s", err.Error())
+		return nil, http.StatusConflict, newRequestError(ErrBuildRequest, "get session token", err)
 	}
 	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 	req.Header.Add("Content-Length", strconv.Itoa(len(encodedData)))
 
-	client := GetHTTPClient(time.Second*30, c.Config.TlsSkipVerify)
-	response, err := client.Do(req)
-
-	body, e := io.ReadAll(response.Body)
-	if e != nil {
-		log.Fatalf("error reading platform token response: %s", err.Error())
+	response, err := c.httpClient().Do(req)
+	if err != nil {
+		return nil, http.StatusBadGateway, newRequestError(ErrSendRequest, "get session token", err)
 	}
 	defer response.Body.Close()
 
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, response.StatusCode, newRequestError(ErrReadResponse, "get session token", err)
+	}
+
+	if response.StatusCode >= 300 {
+		return nil, response.StatusCode, newAPIError(response, body)
+	}
+
 	var idresp IDTenantResponse
 	err = json.Unmarshal(body, &idresp)
 	if err != nil {
-		log.Fatalf("failed to parse json body for platform token: %s\n", err.Error())
+		return nil, response.StatusCode, newRequestError(ErrDecodeResponse, "get session token", err)
 	}
 
 	if idresp.Error != "" {
-		return nil, response.StatusCode, fmt.Errorf("error getting token: (%s) %s", idresp.Error, idresp.ErrorDescription)
+		return nil, response.StatusCode, &APIError{
+			StatusCode:   response.StatusCode,
+			ErrorCode:    idresp.Error,
+			ErrorMessage: idresp.ErrorDescription,
+			Method:       req.Method,
+			URL:          req.URL.Redacted(),
+			Body:         string(body),
+		}
 	}
 
 	sess := Session{
@@ -99,10 +117,76 @@ func (c *Client) GetSession() (*Session, int, error) {
 }
 
 func (c *Client) RefreshSession() error {
-	session, status, err := c.GetSession()
-	if err == nil && status >= 300 {
-		err = fmt.Errorf("failed to get session token: %d", status)
-	}
+	return c.RefreshSessionWithContext(context.Background())
+}
+
+// RefreshSessionWithContext is RefreshSession with a context that can cancel the token request
+func (c *Client) RefreshSessionWithContext(ctx context.Context) error {
+	session, err := c.fetchSession(ctx)
+	c.sessionMu.Lock()
 	c.Session = session
+	c.sessionMu.Unlock()
 	return err
 }
+
+// sessionRefreshMargin is how long before Expiration a token is renewed
+const sessionRefreshMargin = time.Minute
+
+// sessionCall is an in-flight platform token request shared by all waiters
+type sessionCall struct {
+	done chan struct{}
+	sess *Session
+	err  error
+}
+
+func (s *Session) usable() bool {
+	return s != nil && s.Token != "" && time.Now().Add(sessionRefreshMargin).Before(s.Expiration)
+}
+
+// canRefreshSession reports whether the client has credentials to get a token
+func (c *Client) canRefreshSession() bool {
+	return c.Config != nil && c.Config.IdTenantUrl != "" && c.Config.User != ""
+}
+
+func (c *Client) fetchSession(ctx context.Context) (*Session, error) {
+	session, _, err := c.GetSessionWithContext(ctx)
+	return session, err
+}
+
+// currentSession returns a session that is not about to expire, requesting a
+// new token when needed.  If stale is not nil, the token was rejected and is
+// replaced unless another goroutine already did so.  Concurrent callers share
+// a single token request.
+func (c *Client) currentSession(ctx context.Context, stale *Session) (*Session, error) {
+	c.sessionMu.Lock()
+	sess := c.Session
+	if !c.canRefreshSession() || (stale == nil && sess.usable()) || (stale != nil && sess != stale) {
+		c.sessionMu.Unlock()
+		return sess, nil
+	}
+	call := c.sessionCall
+	if call == nil {
+		call = &sessionCall{done: make(chan struct{})}
+		c.sessionCall = call
+		// the token request outlives any single caller's cancellation, it is
+		// bounded by the http client timeout
+		go func() {
+			call.sess, call.err = c.fetchSession(context.WithoutCancel(ctx))
+			c.sessionMu.Lock()
+			if call.err == nil {
+				c.Session = call.sess
+			}
+			c.sessionCall = nil
+			c.sessionMu.Unlock()
+			close(call.done)
+		}()
+	}
+	c.sessionMu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.sess, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,83 @@
+package pam
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newTestServer starts a stand-in for both the Identity tenant and Privilege Cloud
+func newTestServer(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// closedServerURL returns the url of a server that is no longer listening
+func closedServerURL(t *testing.T) string {
+	t.Helper()
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+	return srv.URL
+}
+
+// truncatedBody promises more body than it sends, so reading it fails
+func truncatedBody(w http.ResponseWriter, r *http.Request) {
+	conn, buf, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		panic(err)
+	}
+	fmt.Fprint(buf, "HTTP/1.1 200 OK\r\nContent-Type: application/json\r\nContent-Length: 100\r\n\r\n{")
+	buf.Flush()
+	conn.Close()
+}
+
+func TestGetSessionErrors(t *testing.T) {
+	badjson := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "not json")
+	})
+	truncated := newTestServer(t, truncatedBody)
+
+	tests := []struct {
+		name     string
+		tenant   string
+		wantKind error
+	}{
+		{"build", "http://bad host", ErrBuildRequest},
+		{"send", closedServerURL(t), ErrSendRequest},
+		{"read", truncated.URL, ErrReadResponse},
+		{"decode", badjson.URL, ErrDecodeResponse},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient(tt.tenant, NewConfig(tt.tenant, tt.tenant, "user", "pass"))
+			sess, _, err := client.GetSession()
+			if !errors.Is(err, tt.wantKind) {
+				t.Fatalf("GetSession() error = %v, want %v", err, tt.wantKind)
+			}
+			if sess != nil {
+				t.Errorf("GetSession() session = %+v, want nil", sess)
+			}
+		})
+	}
+}
+
+func TestGetSessionTokenError(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error":"invalid_client","error_description":"bad secret"}`)
+	})
+
+	client := NewClient(srv.URL, NewConfig(srv.URL, srv.URL, "user", "pass"))
+	_, status, err := client.GetSession()
+	var apierr *APIError
+	if !errors.As(err, &apierr) {
+		t.Fatalf("GetSession() error = %v, want *APIError", err)
+	}
+	if status != http.StatusBadRequest || apierr.ErrorCode != "invalid_client" {
+		t.Errorf("GetSession() = (%d, %q), want (400, invalid_client)", status, apierr.ErrorCode)
+	}
+}